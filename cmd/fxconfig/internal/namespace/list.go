@@ -16,7 +16,11 @@ import (
 )
 
 func List(config *queryservice.Config) error {
-	conn, err := queryservice.GrpcClient(config)
+	if len(config.Endpoints) == 0 {
+		return queryservice.ErrNoEndpoints
+	}
+	// all the replicas serve the same namespaces
+	conn, err := queryservice.GrpcClient(config.Endpoints[0])
 	if err != nil {
 		return fmt.Errorf("cannot get grpc client: %w", err)
 	}
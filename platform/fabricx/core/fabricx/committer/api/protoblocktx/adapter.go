@@ -25,4 +25,11 @@ type Marshaller interface {
 	MarshalNamespacePolicy(NamespacePolicy) ([]byte, error)
 	MarshalNamespaceID(driver.Namespace) ([]byte, error)
 	IsStatusValid(b byte) bool
+	DecodeStatus(b byte) Status
+}
+
+// Validator is implemented by the marshallers that can check a transaction
+// against the structural rules of their committer before it is broadcast.
+type Validator interface {
+	ValidateTx(Tx) error
 }
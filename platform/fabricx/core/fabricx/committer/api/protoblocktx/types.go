@@ -130,6 +130,15 @@ type Write interface {
 	GetValue() []byte
 }
 
+// Status is the status the committer assigned to a transaction, as recorded in the transactions filter of a block.
+// MVCCConflict tells whether the transaction was aborted because it read stale versions.
+type Status struct {
+	Code         int32
+	Name         string
+	Valid        bool
+	MVCCConflict bool
+}
+
 type namespacePolicy struct {
 	Scheme    string
 	PublicKey []byte
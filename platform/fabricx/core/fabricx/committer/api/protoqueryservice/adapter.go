@@ -9,6 +9,7 @@ package protoqueryservice
 import (
 	"context"
 
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
 	"google.golang.org/grpc"
@@ -20,6 +21,9 @@ func NewProvider(configService driver.ConfigService) (*types.ServiceProvider[Que
 	return types.NewProvider[QueryServiceClientProvider](configService)
 }
 
+// ErrNotSupported is returned by the calls the committer does not implement.
+var ErrNotSupported = errors.New("not supported by the committer")
+
 type QueryServiceClientProvider interface {
 	GetClient(*grpc.ClientConn) QueryServiceClient
 }
@@ -30,4 +34,11 @@ type QueryServiceClient interface {
 	BeginView(ctx context.Context, in ViewParameters, opts ...grpc.CallOption) (View, error)
 	EndView(ctx context.Context, in View, opts ...grpc.CallOption) (View, error)
 	GetPolicies(ctx context.Context, opts ...grpc.CallOption) (Policies, error)
+	GetRowsRange(ctx context.Context, in RangeQuery, opts ...grpc.CallOption) (RowsStream, error)
+}
+
+// RowsStream returns the pages of rows selected by a range query, in key order.
+// Recv returns io.EOF after the last page.
+type RowsStream interface {
+	Recv() ([]Row, error)
 }
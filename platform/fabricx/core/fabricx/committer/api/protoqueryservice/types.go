@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package protoqueryservice
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
@@ -32,6 +33,58 @@ type Query interface {
 	GetNamespaces() []QueryNamespace
 }
 
+type rangeQuery struct {
+	View     View
+	NsId     driver.Namespace
+	StartKey []byte
+	EndKey   []byte
+	PageSize uint32
+}
+
+// NewRangeQuery selects the rows of the namespace with keys in [StartKey, EndKey).
+// An empty key leaves the range open on that side. A zero PageSize lets the committer choose it.
+func NewRangeQuery(View View, NsId driver.Namespace, StartKey, EndKey []byte, PageSize uint32) *rangeQuery {
+	return &rangeQuery{
+		View:     View,
+		NsId:     NsId,
+		StartKey: StartKey,
+		EndKey:   EndKey,
+		PageSize: PageSize,
+	}
+}
+
+// NewPrefixQuery selects the rows of the namespace with keys starting with Prefix.
+func NewPrefixQuery(View View, NsId driver.Namespace, Prefix []byte, PageSize uint32) *rangeQuery {
+	return NewRangeQuery(View, NsId, Prefix, PrefixEnd(Prefix), PageSize)
+}
+
+// PrefixEnd returns the smallest key greater than all the keys starting with prefix,
+// or nil, that is no upper bound, when there is none.
+func PrefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+func (q *rangeQuery) GetView() View             { return q.View }
+func (q *rangeQuery) GetNsId() driver.Namespace { return q.NsId }
+func (q *rangeQuery) GetStartKey() []byte       { return q.StartKey }
+func (q *rangeQuery) GetEndKey() []byte         { return q.EndKey }
+func (q *rangeQuery) GetPageSize() uint32       { return q.PageSize }
+
+type RangeQuery interface {
+	GetView() View
+	GetNsId() driver.Namespace
+	GetStartKey() []byte
+	GetEndKey() []byte
+	GetPageSize() uint32
+}
+
 type view struct {
 	Id string
 }
@@ -80,6 +133,14 @@ type QueryNamespace interface {
 
 type IsoLevel int32
 
+// The isolation levels of a view, as defined by the committer.
+const (
+	Serializable IsoLevel = iota
+	RepeatableRead
+	ReadCommitted
+	ReadUncommitted
+)
+
 type viewParameters struct {
 	IsoLevel      IsoLevel
 	NonDeferrable bool
@@ -94,6 +155,10 @@ func NewViewParameters(IsoLevel IsoLevel, NonDeferrable bool, Timeout time.Durat
 	}
 }
 
+func (p *viewParameters) GetIsoLevel() IsoLevel     { return p.IsoLevel }
+func (p *viewParameters) GetNonDeferrable() bool    { return p.NonDeferrable }
+func (p *viewParameters) GetTimeout() time.Duration { return p.Timeout }
+
 type ViewParameters interface {
 	GetIsoLevel() IsoLevel
 	GetNonDeferrable() bool
@@ -15,42 +15,71 @@ import (
 	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/committer"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
 	"github.com/hyperledger/fabric-protos-go/common"
-	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
 )
 
 var logger = logging.MustGetLogger("fabricx.committer")
 
-func RegisterTransactionHandler(com *committer.Committer) {
-	h := NewHandler(com)
+// ErrMVCCConflict is the cause of the AbortError of transactions aborted because of an MVCC conflict.
+var ErrMVCCConflict = errors.New("mvcc conflict")
+
+// AbortError is the finality error of a transaction the committer aborted.
+// It carries the fabricx status, so that applications can tell an MVCC conflict from an invalid signature.
+type AbortError struct {
+	TxID   string
+	Status protoblocktx.Status
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("transaction [%s] aborted with status [%s (%d)]", e.TxID, e.Status.Name, e.Status.Code)
+}
+
+func (e *AbortError) Unwrap() error {
+	if e.Status.MVCCConflict {
+		return ErrMVCCConflict
+	}
+	return nil
+}
+
+// IsMVCCConflict tells whether the passed error reports a transaction aborted because of an MVCC conflict.
+func IsMVCCConflict(err error) bool {
+	return errors.Is(err, ErrMVCCConflict)
+}
+
+type statusKey struct{}
+
+// StatusFromContext returns the fabricx status of the transaction whose finality event carries the passed context.
+// The finality listeners receive it in the context passed to OnStatus, as the fabric validation code
+// of the event only tells valid from invalid.
+func StatusFromContext(ctx context.Context) (protoblocktx.Status, bool) {
+	status, ok := ctx.Value(statusKey{}).(protoblocktx.Status)
+	return status, ok
+}
+
+func RegisterTransactionHandler(com *committer.Committer, marshaller protoblocktx.Marshaller) {
+	h := NewHandler(com, marshaller)
 
 	com.Handlers[common.HeaderType_MESSAGE] = h.HandleFabricxTransaction
 }
 
-func NewHandler(com *committer.Committer) *handler {
-	return &handler{committer: com}
+func NewHandler(com *committer.Committer, marshaller protoblocktx.Marshaller) *handler {
+	return &handler{committer: com, marshaller: marshaller}
 }
 
 type handler struct {
-	committer *committer.Committer
+	committer  *committer.Committer
+	marshaller protoblocktx.Marshaller
 }
 
 func (h *handler) HandleFabricxTransaction(ctx context.Context, block *common.BlockMetadata, tx committer.CommitTx) (*committer.FinalityEvent, error) {
 	logger.Debugf("Handle a new fabricx transaction [channel=%s] with [txID=%s]", h.committer.ChannelConfig.ID(), tx.TxID)
 
-	if len(block.Metadata) < int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
-		return nil, fmt.Errorf("block metadata lacks transaction filter")
+	event, status, err := h.newFinalityEvent(ctx, block, tx)
+	if err != nil {
+		return nil, err
 	}
 
-	fabricValidationCode := committer.ValidationFlags(block.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])[tx.TxNum]
-	event := &committer.FinalityEvent{
-		Ctx:               ctx,
-		TxID:              tx.TxID,
-		ValidationCode:    convertValidationCode(int32(fabricValidationCode)),
-		ValidationMessage: peer.TxValidationCode_name[int32(fabricValidationCode)],
-	}
-
-	switch peer.TxValidationCode(fabricValidationCode) {
-	case peer.TxValidationCode_VALID:
+	if status.Valid {
 		processed, err := h.committer.CommitEndorserTransaction(ctx, event.TxID, tx.BlkNum, tx.TxNum, tx.Envelope, event)
 		if err != nil {
 			if errors.HasCause(err, committer.ErrDiscardTX) {
@@ -58,8 +87,7 @@ func (h *handler) HandleFabricxTransaction(ctx context.Context, block *common.Bl
 				event.ValidationCode = driver.Invalid
 				event.ValidationMessage = err.Error()
 
-				// escaping the switch and discard
-				break
+				return h.discard(ctx, tx, event)
 			}
 			return nil, fmt.Errorf("failed committing transaction [%s]: %w", event.TxID, err)
 		}
@@ -72,19 +100,46 @@ func (h *handler) HandleFabricxTransaction(ctx context.Context, block *common.Bl
 		return event, nil
 	}
 
+	event, err = h.discard(ctx, tx, event)
+	if err != nil {
+		return nil, err
+	}
+	// the committer aborted the transaction, report why
+	event.Err = &AbortError{TxID: tx.TxID, Status: status}
+	return event, nil
+}
+
+// newFinalityEvent decodes the fabricx status of the transaction from the transactions filter of the block,
+// and returns the finality event carrying it.
+func (h *handler) newFinalityEvent(ctx context.Context, block *common.BlockMetadata, tx committer.CommitTx) (*committer.FinalityEvent, protoblocktx.Status, error) {
+	if len(block.Metadata) <= int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return nil, protoblocktx.Status{}, fmt.Errorf("block metadata lacks transaction filter")
+	}
+	filter := block.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	if tx.TxNum >= uint64(len(filter)) {
+		return nil, protoblocktx.Status{}, fmt.Errorf("transaction filter lacks the status of transaction [%d]", tx.TxNum)
+	}
+
+	status := h.marshaller.DecodeStatus(filter[tx.TxNum])
+	return &committer.FinalityEvent{
+		Ctx:               context.WithValue(ctx, statusKey{}, status),
+		TxID:              tx.TxID,
+		ValidationCode:    convertValidationCode(status),
+		ValidationMessage: status.Name,
+	}, status, nil
+}
+
+func (h *handler) discard(ctx context.Context, tx committer.CommitTx, event *committer.FinalityEvent) (*committer.FinalityEvent, error) {
 	logger.Warnf("Discarding transaction %s", tx.TxID)
 	if err := h.committer.DiscardEndorserTransaction(ctx, event.TxID, tx.BlkNum, tx.Raw, event); err != nil {
 		return nil, fmt.Errorf("failed discarding transaction [%s]: %w", event.TxID, err)
 	}
-
 	return event, nil
 }
 
-func convertValidationCode(vc int32) driver.ValidationCode {
-	switch peer.TxValidationCode(vc) {
-	case peer.TxValidationCode_VALID:
+func convertValidationCode(status protoblocktx.Status) driver.ValidationCode {
+	if status.Valid {
 		return driver.Valid
-	default:
-		return driver.Invalid
 	}
+	return driver.Invalid
 }
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package committer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/committer"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger/fabric-protos-go/common"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+func blockMetadata(statuses ...v2.Status) *common.BlockMetadata {
+	filter := make([]byte, len(statuses))
+	for i, status := range statuses {
+		filter[i] = byte(status)
+	}
+	md := &common.BlockMetadata{Metadata: make([][]byte, len(common.BlockMetadataIndex_name))}
+	md.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+	return md
+}
+
+func TestNewFinalityEvent(t *testing.T) {
+	h := &handler{marshaller: v2.NewMarshallerAdapter()}
+	block := blockMetadata(v2.Status_COMMITTED, v2.Status_ABORTED_MVCC_CONFLICT)
+
+	event, status, err := h.newFinalityEvent(context.Background(), block, committer.CommitTx{TxID: "tx1", TxNum: 0})
+	require.NoError(t, err)
+	require.Equal(t, driver.Valid, event.ValidationCode)
+	require.True(t, status.Valid)
+
+	// the MVCC conflict reaches the listeners through the context of the event
+	event, status, err = h.newFinalityEvent(context.Background(), block, committer.CommitTx{TxID: "tx2", TxNum: 1})
+	require.NoError(t, err)
+	require.Equal(t, "tx2", event.TxID)
+	require.Equal(t, driver.Invalid, event.ValidationCode)
+	require.Equal(t, v2.Status_ABORTED_MVCC_CONFLICT.String(), event.ValidationMessage)
+	fromCtx, ok := StatusFromContext(event.Ctx)
+	require.True(t, ok)
+	require.Equal(t, status, fromCtx)
+	require.Equal(t, int32(v2.Status_ABORTED_MVCC_CONFLICT), fromCtx.Code)
+	require.True(t, fromCtx.MVCCConflict)
+	require.True(t, IsMVCCConflict(&AbortError{TxID: "tx2", Status: fromCtx}))
+
+	_, ok = StatusFromContext(context.Background())
+	require.False(t, ok)
+
+	_, _, err = h.newFinalityEvent(context.Background(), block, committer.CommitTx{TxID: "tx3", TxNum: 2})
+	require.Error(t, err)
+	_, _, err = h.newFinalityEvent(context.Background(), &common.BlockMetadata{}, committer.CommitTx{TxID: "tx1"})
+	require.Error(t, err)
+}
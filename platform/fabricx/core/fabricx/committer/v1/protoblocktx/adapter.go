@@ -93,6 +93,15 @@ func (a *marshallerAdapter) MarshalNamespaceID(ns driver.Namespace) ([]byte, err
 
 func (a *marshallerAdapter) IsStatusValid(b byte) bool { return b == byte(Status_COMMITTED) }
 
+func (a *marshallerAdapter) DecodeStatus(b byte) api.Status {
+	return api.Status{
+		Code:         int32(b),
+		Name:         Status(b).String(),
+		Valid:        a.IsStatusValid(b),
+		MVCCConflict: b == byte(Status_ABORTED_MVCC_CONFLICT),
+	}
+}
+
 func mapRead(r api.Read) *Read { return &Read{Key: r.GetKey(), Version: r.GetVersion()} }
 
 func mapWrite(w api.Write) *Write { return &Write{Key: w.GetKey(), Value: w.GetValue()} }
@@ -80,7 +80,11 @@ func (a *serviceAdapter) EndView(ctx context.Context, in api.View, opts ...grpc.
 }
 
 func (*serviceAdapter) GetPolicies(context.Context, ...grpc.CallOption) (api.Policies, error) {
-	panic("not implemented")
+	return nil, api.ErrNotSupported
+}
+
+func (*serviceAdapter) GetRowsRange(context.Context, api.RangeQuery, ...grpc.CallOption) (api.RowsStream, error) {
+	return nil, api.ErrNotSupported
 }
 
 func mapView(view api.View) *View {
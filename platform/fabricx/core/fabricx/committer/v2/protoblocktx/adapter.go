@@ -72,6 +72,15 @@ func (a *marshallerAdapter) IsStatusValid(b byte) bool {
 	return b == byte(Status_COMMITTED)
 }
 
+func (a *marshallerAdapter) DecodeStatus(b byte) api.Status {
+	return api.Status{
+		Code:         int32(b),
+		Name:         Status(b).String(),
+		Valid:        a.IsStatusValid(b),
+		MVCCConflict: b == byte(Status_ABORTED_MVCC_CONFLICT),
+	}
+}
+
 // maxNamespaceIDLength defines the maximum number of characters allowed for namespace IDs.
 // PostgreSQL limits identifiers to NAMEDATALEN-1, where NAMEDATALEL=64.
 // The namespace tables have the prefix 'ns_', thus there are 60 characters remaining.
@@ -44,3 +44,18 @@ func TestNamespaceBadNames(t *testing.T) {
 	err = validateNamespaceID("")
 	require.Error(t, err)
 }
+
+func TestDecodeStatus(t *testing.T) {
+	adapter := NewMarshallerAdapter()
+
+	status := adapter.DecodeStatus(byte(Status_COMMITTED))
+	require.True(t, status.Valid)
+	require.False(t, status.MVCCConflict)
+
+	status = adapter.DecodeStatus(byte(Status_ABORTED_MVCC_CONFLICT))
+	require.Equal(t, api.Status{Code: int32(Status_ABORTED_MVCC_CONFLICT), Name: "ABORTED_MVCC_CONFLICT", MVCCConflict: true}, status)
+
+	status = adapter.DecodeStatus(byte(Status_ABORTED_SIGNATURE_INVALID))
+	require.False(t, status.Valid)
+	require.False(t, status.MVCCConflict)
+}
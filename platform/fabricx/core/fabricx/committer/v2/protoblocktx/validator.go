@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protoblocktx
+
+import (
+	"fmt"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+)
+
+// ValidationError reports the status the committer would abort a transaction with.
+type ValidationError struct {
+	Status Status
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Reason)
+}
+
+// ValidateTx runs the structural checks of the committer on the passed transaction,
+// so that malformed transactions are rejected before being broadcast.
+func (a *marshallerAdapter) ValidateTx(tx api.Tx) error {
+	if status, reason := ValidateTx(tx); status != Status_COMMITTED {
+		return &ValidationError{Status: status, Reason: reason}
+	}
+	return nil
+}
+
+// ValidateTx returns the status the committer would abort the transaction with, together with a reason.
+// Status_COMMITTED means that the transaction is well-formed, not that it will be committed.
+func ValidateTx(tx api.Tx) (Status, string) {
+	if len(tx.GetId()) == 0 {
+		return Status_ABORTED_MISSING_TXID, "the transaction has no id"
+	}
+
+	seen := make(map[driver.Namespace]struct{}, len(tx.GetNamespaces()))
+	for _, ns := range tx.GetNamespaces() {
+		if len(ns.GetNsVersion()) == 0 {
+			return Status_ABORTED_MISSING_NAMESPACE_VERSION, fmt.Sprintf("namespace [%s] has no version", ns.GetNsId())
+		}
+		if err := validateNamespaceID(ns.GetNsId()); err != nil {
+			return Status_ABORTED_NAMESPACE_ID_INVALID, fmt.Sprintf("namespace id [%s] is invalid", ns.GetNsId())
+		}
+		if _, ok := seen[ns.GetNsId()]; ok {
+			return Status_ABORTED_DUPLICATE_NAMESPACE, fmt.Sprintf("namespace [%s] appears more than once", ns.GetNsId())
+		}
+		seen[ns.GetNsId()] = struct{}{}
+
+		if len(ns.GetReadWrites()) == 0 && len(ns.GetBlindWrites()) == 0 {
+			return Status_ABORTED_NO_WRITES, fmt.Sprintf("namespace [%s] has no writes", ns.GetNsId())
+		}
+		if ns.GetNsId() == api.MetaNamespace {
+			if status, reason := validateMetaNamespace(ns); status != Status_COMMITTED {
+				return status, reason
+			}
+		}
+	}
+	return Status_COMMITTED, ""
+}
+
+// validateMetaNamespace checks that the writes to the meta namespace define namespace policies.
+func validateMetaNamespace(ns api.TxNamespace) (Status, string) {
+	if len(ns.GetBlindWrites()) != 0 {
+		return Status_ABORTED_BLIND_WRITES_NOT_ALLOWED, fmt.Sprintf("namespace [%s] does not allow blind writes", ns.GetNsId())
+	}
+	for _, rw := range ns.GetReadWrites() {
+		nsID := driver.Namespace(rw.GetKey())
+		if nsID == api.MetaNamespace || validateNamespaceID(nsID) != nil {
+			return Status_ABORTED_NAMESPACE_ID_INVALID, fmt.Sprintf("namespace id [%s] is invalid", nsID)
+		}
+		policy := &NamespacePolicy{}
+		if err := proto.Unmarshal(rw.GetValue(), policy); err != nil {
+			return Status_ABORTED_NAMESPACE_POLICY_INVALID, fmt.Sprintf("policy of namespace [%s] cannot be decoded: %v", nsID, err)
+		}
+		if len(policy.GetScheme()) == 0 || len(policy.GetPublicKey()) == 0 {
+			return Status_ABORTED_NAMESPACE_POLICY_INVALID, fmt.Sprintf("policy of namespace [%s] is incomplete", nsID)
+		}
+	}
+	return Status_COMMITTED, ""
+}
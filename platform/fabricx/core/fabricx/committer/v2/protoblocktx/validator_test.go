@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protoblocktx
+
+import (
+	"errors"
+	"testing"
+
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTx(t *testing.T) {
+	version := []byte{0}
+	write := []api.Write{api.NewWrite([]byte("key"), []byte("val"))}
+	policy := protoutil.MarshalOrPanic(&NamespacePolicy{Scheme: "ECDSA", PublicKey: []byte("pk")})
+	definePolicy := func(nsID string, value []byte) []api.ReadWrite {
+		return []api.ReadWrite{api.NewReadWrite([]byte(nsID), nil, value)}
+	}
+
+	for name, tc := range map[string]struct {
+		tx       api.Tx
+		expected Status
+	}{
+		"valid": {
+			tx: api.NewTx("tx1", []api.TxNamespace{
+				api.NewTxNamespace("iou", version, nil, nil, write),
+				api.NewTxNamespace(api.MetaNamespace, version, nil, definePolicy("iou", policy), nil),
+			}, nil),
+			expected: Status_COMMITTED,
+		},
+		"missing tx id": {
+			tx:       api.NewTx("", []api.TxNamespace{api.NewTxNamespace("iou", version, nil, nil, write)}, nil),
+			expected: Status_ABORTED_MISSING_TXID,
+		},
+		"missing namespace version": {
+			tx:       api.NewTx("tx1", []api.TxNamespace{api.NewTxNamespace("iou", nil, nil, nil, write)}, nil),
+			expected: Status_ABORTED_MISSING_NAMESPACE_VERSION,
+		},
+		"invalid namespace id": {
+			tx:       api.NewTx("tx1", []api.TxNamespace{api.NewTxNamespace("IOU", version, nil, nil, write)}, nil),
+			expected: Status_ABORTED_NAMESPACE_ID_INVALID,
+		},
+		"duplicate namespace": {
+			tx: api.NewTx("tx1", []api.TxNamespace{
+				api.NewTxNamespace("iou", version, nil, nil, write),
+				api.NewTxNamespace("iou", version, nil, nil, write),
+			}, nil),
+			expected: Status_ABORTED_DUPLICATE_NAMESPACE,
+		},
+		"no writes": {
+			tx: api.NewTx("tx1", []api.TxNamespace{
+				api.NewTxNamespace("iou", version, []api.Read{api.NewRead([]byte("key"), version)}, nil, nil),
+			}, nil),
+			expected: Status_ABORTED_NO_WRITES,
+		},
+		"blind writes to meta namespace": {
+			tx:       api.NewTx("tx1", []api.TxNamespace{api.NewTxNamespace(api.MetaNamespace, version, nil, nil, write)}, nil),
+			expected: Status_ABORTED_BLIND_WRITES_NOT_ALLOWED,
+		},
+		"invalid policy": {
+			tx:       api.NewTx("tx1", []api.TxNamespace{api.NewTxNamespace(api.MetaNamespace, version, nil, definePolicy("iou", []byte{0xff}), nil)}, nil),
+			expected: Status_ABORTED_NAMESPACE_POLICY_INVALID,
+		},
+		"invalid policy namespace id": {
+			tx:       api.NewTx("tx1", []api.TxNamespace{api.NewTxNamespace(api.MetaNamespace, version, nil, definePolicy("IOU", policy), nil)}, nil),
+			expected: Status_ABORTED_NAMESPACE_ID_INVALID,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			status, reason := ValidateTx(tc.tx)
+			require.Equal(t, tc.expected, status, reason)
+
+			err := NewMarshallerAdapter().ValidateTx(tc.tx)
+			if tc.expected == Status_COMMITTED {
+				require.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			require.Equal(t, tc.expected, validationErr.Status)
+			require.NotEmpty(t, validationErr.Reason)
+		})
+	}
+}
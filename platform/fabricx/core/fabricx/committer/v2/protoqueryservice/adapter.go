@@ -13,6 +13,8 @@ import (
 	protoblocktx "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/utils"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type queryServiceClientProvider struct{}
@@ -67,11 +69,24 @@ func mapView(view api.View) *View {
 func (a *serviceAdapter) GetPolicies(ctx context.Context, opts ...grpc.CallOption) (api.Policies, error) {
 	policies, err := a.s.GetPolicies(ctx, &Empty{}, opts...)
 	if err != nil {
-		return nil, err
+		return nil, notSupported(err)
 	}
 	return api.NewPolicies(utils.Map(policies.GetPolicies(), func(p *protoblocktx.PolicyItem) api.PolicyItem { return p })), nil
 }
 
+// GetRowsRange returns api.ErrNotSupported, as the committer query service has no range queries.
+func (*serviceAdapter) GetRowsRange(context.Context, api.RangeQuery, ...grpc.CallOption) (api.RowsStream, error) {
+	return nil, api.ErrNotSupported
+}
+
+// notSupported returns api.ErrNotSupported for the calls the committer does not implement
+func notSupported(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return api.ErrNotSupported
+	}
+	return err
+}
+
 func mapRowsNamespace(namespace *RowsNamespace) api.RowsNamespace {
 	return api.NewRowsNamespace(
 		namespace.GetNsId(),
@@ -0,0 +1,59 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protoqueryservice_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// oldQueryService is a committer predating the policies
+type oldQueryService struct {
+	protoqueryservice.UnimplementedQueryServiceServer
+}
+
+func (oldQueryService) GetRows(context.Context, *protoqueryservice.Query) (*protoqueryservice.Rows, error) {
+	return &protoqueryservice.Rows{}, nil
+}
+
+func startOldQueryService(t *testing.T) api.QueryServiceClient {
+	t.Helper()
+	desc := protoqueryservice.QueryService_ServiceDesc
+	desc.Methods = desc.Methods[:1]
+	server := grpc.NewServer()
+	server.RegisterService(&desc, oldQueryService{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return protoqueryservice.NewQueryServiceClientProvider().GetClient(conn)
+}
+
+func TestAdapterNotSupported(t *testing.T) {
+	client := startOldQueryService(t)
+	ctx := context.Background()
+
+	_, err := client.GetRows(ctx, api.NewQuery(nil, []api.QueryNamespace{api.NewQueryNamespace("ns1", [][]byte{[]byte("key1")})}))
+	require.NoError(t, err)
+
+	_, err = client.GetPolicies(ctx)
+	require.ErrorIs(t, err, api.ErrNotSupported)
+
+	_, err = client.GetRowsRange(ctx, api.NewRangeQuery(nil, "ns1", nil, nil, 0))
+	require.ErrorIs(t, err, api.ErrNotSupported)
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/finality"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
 	"github.com/hyperledger/fabric-protos-go/common"
-	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
 )
 
@@ -33,7 +32,7 @@ type ledger struct {
 	marshaller protoblocktx.Marshaller
 
 	mu        sync.RWMutex
-	statuses  map[string]pb.TxValidationCode
+	statuses  map[string]protoblocktx.Status
 	blockNums map[string]driver.BlockNum
 }
 
@@ -42,21 +41,16 @@ var _ driver.Ledger = (*ledger)(nil)
 
 func New(marshaller protoblocktx.Marshaller) *ledger {
 	l := &ledger{
-		statuses:   map[string]pb.TxValidationCode{},
+		statuses:   map[string]protoblocktx.Status{},
 		blockNums:  map[string]driver.BlockNum{},
 		marshaller: marshaller,
 	}
 	return l
 }
 
-var txStatusMapping = map[bool]pb.TxValidationCode{
-	true:  pb.TxValidationCode_VALID,
-	false: pb.TxValidationCode_INVALID_OTHER_REASON,
-}
-
 func (c *ledger) OnBlock(_ context.Context, block *common.Block) (bool, error) {
 	logger.Debugf("Received block [blockNo=%d]", block.Header.Number)
-	newStatuses := make(map[string]pb.TxValidationCode, len(block.Data.Data))
+	newStatuses := make(map[string]protoblocktx.Status, len(block.Data.Data))
 	newBlockNums := make(map[string]driver.BlockNum, len(block.Data.Data))
 
 	for i, tx := range block.Data.Data {
@@ -65,10 +59,10 @@ func (c *ledger) OnBlock(_ context.Context, block *common.Block) (bool, error) {
 			return false, err
 		}
 
-		status := txStatusMapping[c.marshaller.IsStatusValid(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER][i])]
+		status := c.marshaller.DecodeStatus(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER][i])
 
-		logger.Debugf("Unmarshalled [blockNum=%d,pos=%d, txID=%s, status=%v]",
-			block.Header.Number, i, chdr.TxId, status)
+		logger.Debugf("Unmarshalled [blockNum=%d,pos=%d, txID=%s, status=%s]",
+			block.Header.Number, i, chdr.TxId, status.Name)
 		newStatuses[chdr.TxId] = status
 		newBlockNums[chdr.TxId] = block.Header.Number
 	}
@@ -98,8 +92,8 @@ func (c *ledger) GetTransactionByID(txID string) (driver.ProcessedTransaction, e
 		status, ok := c.statuses[txID]
 		c.mu.RUnlock()
 		if ok {
-			logger.Debugf("Transaction [%s] found with status [%d]", txID, int32(status))
-			return &liteTx{txID: txID, validationCode: int32(status)}, nil
+			logger.Debugf("Transaction [%s] found with status [%s]", txID, status.Name)
+			return &liteTx{txID: txID, status: status}, nil
 		}
 		logger.Warnf("transaction [%s] not found. retrying...", txID)
 		time.Sleep(1 * time.Second)
@@ -130,9 +124,10 @@ func (c *ledger) GetBlockByNumber(number uint64) (driver.Block, error) {
 	panic("GetBlockByNumber >> implement me")
 }
 
+// liteTx reports the fabricx status of the transaction as its validation code.
 type liteTx struct {
-	txID           string
-	validationCode int32
+	txID   string
+	status protoblocktx.Status
 }
 
 func (t *liteTx) TxID() string {
@@ -144,11 +139,16 @@ func (t *liteTx) Results() []byte {
 }
 
 func (t *liteTx) ValidationCode() int32 {
-	return t.validationCode
+	return t.status.Code
 }
 
 func (t *liteTx) IsValid() bool {
-	return t.validationCode == int32(pb.TxValidationCode_VALID)
+	return t.status.Valid
+}
+
+// Status returns the status the committer assigned to the transaction.
+func (t *liteTx) Status() protoblocktx.Status {
+	return t.status
 }
 
 func (t *liteTx) Envelope() []byte {
@@ -0,0 +1,53 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/common"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func envelope(t *testing.T, txID string) []byte {
+	t.Helper()
+	chdr := protoutil.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "channel", 0)
+	chdr.TxId = txID
+	payload := &common.Payload{Header: protoutil.MakePayloadHeader(chdr, &common.SignatureHeader{})}
+	return protoutil.MarshalOrPanic(&common.Envelope{Payload: protoutil.MarshalOrPanic(payload)})
+}
+
+func TestOnBlockKeepsStatus(t *testing.T) {
+	l := New(v2.NewMarshallerAdapter())
+
+	block := protoutil.NewBlock(7, nil)
+	block.Data.Data = [][]byte{envelope(t, "tx1"), envelope(t, "tx2")}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{
+		byte(v2.Status_COMMITTED),
+		byte(v2.Status_ABORTED_MVCC_CONFLICT),
+	}
+	_, err := l.OnBlock(context.Background(), block)
+	require.NoError(t, err)
+
+	tx, err := l.GetTransactionByID("tx1")
+	require.NoError(t, err)
+	require.True(t, tx.IsValid())
+	require.Equal(t, int32(v2.Status_COMMITTED), tx.ValidationCode())
+
+	tx, err = l.GetTransactionByID("tx2")
+	require.NoError(t, err)
+	require.False(t, tx.IsValid())
+	require.Equal(t, int32(v2.Status_ABORTED_MVCC_CONFLICT), tx.ValidationCode())
+	require.Equal(t, "ABORTED_MVCC_CONFLICT", tx.(*liteTx).Status().Name)
+
+	blockNum, err := l.GetBlockNumberByTxID("tx2")
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), blockNum)
+}
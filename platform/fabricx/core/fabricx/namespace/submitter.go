@@ -73,12 +73,9 @@ func (s *submitter) Submit(network, channel string, namespaces []protoblocktx.Tx
 	txID := s.txIDCalculator(nonce, serializedCreator)
 
 	// compute namespace tx hash
-	sigs := make([][]byte, len(namespaces))
-	for i, namespace := range namespaces {
-		sigs[i], err = signer.Sign(transaction2.HashTxNamespace(txID, namespace))
-		if err != nil {
-			return errors.Wrapf(err, "failed signing tx")
-		}
+	sigs, err := transaction2.SignTxNamespaces(signer, protoblocktx.NewTx(txID, namespaces, nil))
+	if err != nil {
+		return errors.Wrapf(err, "failed signing tx")
 	}
 
 	nsTx := protoblocktx.NewTx(txID, namespaces, sigs)
@@ -87,6 +84,11 @@ func (s *submitter) Submit(network, channel string, namespaces []protoblocktx.Tx
 	if err != nil {
 		return err
 	}
+	if v, ok := adapter.(protoblocktx.Validator); ok {
+		if err := v.ValidateTx(nsTx); err != nil {
+			return errors.Wrapf(err, "invalid transaction")
+		}
+	}
 	txRaw, err := adapter.MarshalTx(nsTx)
 	if err != nil {
 		return errors.Wrapf(err, "failed marshaling transaction")
@@ -22,6 +22,7 @@ import (
 type Provider struct {
 	p               *driver2.Provider
 	adapterProvider protoblocktx.Provider
+	combiners       []transaction.NamedSignatureCombiner
 }
 
 func NewProvider(
@@ -35,6 +36,7 @@ func NewProvider(
 	signerKVS driver.SignerInfoStore,
 	auditInfoKVS driver.AuditInfoStore,
 	adapterProvider protoblocktx.Provider,
+	combiners []transaction.NamedSignatureCombiner,
 ) *Provider {
 	return &Provider{
 		p: driver2.NewProvider(
@@ -49,6 +51,7 @@ func NewProvider(
 			kvss,
 		),
 		adapterProvider: adapterProvider,
+		combiners:       combiners,
 	}
 }
 
@@ -62,10 +65,18 @@ func (d *Provider) New(network string, b bool) (fdriver.FabricNetworkService, er
 	if err != nil {
 		return nil, err
 	}
+	aggregator, err := transaction.NewAggregatorFromConfig(net.ConfigService(), d.combiners)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := transaction.NewNamespaceMapperFromConfig(net.ConfigService())
+	if err != nil {
+		return nil, err
+	}
 	txManager := transaction.NewManager(adapter)
 	txManager.AddTransactionFactory(
 		fdriver.EndorserTransaction,
-		transaction.NewTransactionFactory(net, adapter),
+		transaction.NewTransactionFactory(net, adapter, aggregator, net.ConfigService().GetString("transaction.encoding"), mapper),
 	)
 
 	net.(*generic.Network).SetTransactionManager(txManager)
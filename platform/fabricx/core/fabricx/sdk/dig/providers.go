@@ -30,6 +30,7 @@ import (
 	committer2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/ledger"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/transaction"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/transaction/rwset"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
@@ -51,7 +52,8 @@ func NewDriver(in struct {
 	AuditInfoStore  driver.AuditInfoStore
 	AdapterProvider protoblocktx.Provider
 	ChannelProvider ChannelProvider
-	IdentityLoaders []identity.NamedIdentityLoader `group:"identity-loaders"`
+	IdentityLoaders []identity.NamedIdentityLoader       `group:"identity-loaders"`
+	Combiners       []transaction.NamedSignatureCombiner `group:"signature-combiners"`
 },
 ) core.NamedDriver {
 	d := core.NamedDriver{
@@ -67,6 +69,7 @@ func NewDriver(in struct {
 			in.SignerInfoStore,
 			in.AuditInfoStore,
 			in.AdapterProvider,
+			in.Combiners,
 		),
 	}
 	return d
@@ -119,7 +122,11 @@ func NewChannelProvider(in struct {
 			if err != nil {
 				return nil, err
 			}
-			return NewCommitter(nw, channelConfig, vault, envelopeService, ledger, rwsetLoaderService, in.Publisher, channelMembershipService, fabricFinality, fcommitter.NewSerialDependencyResolver(), quiet, flmProvider.NewManager(), in.TracerProvider, in.MetricsProvider)
+			marshaller, err := in.AdapterProvider.Get(nw.Name(), channel)
+			if err != nil {
+				return nil, err
+			}
+			return NewCommitter(nw, channelConfig, vault, envelopeService, ledger, rwsetLoaderService, in.Publisher, channelMembershipService, fabricFinality, fcommitter.NewSerialDependencyResolver(), quiet, flmProvider.NewManager(), in.TracerProvider, in.MetricsProvider, marshaller)
 		},
 		// delivery service constructor
 		func(
@@ -168,7 +175,7 @@ func NewRWSetLoader(channel string, nw fdriver.FabricNetworkService, envelopeSer
 	return rwset.NewLoader(nw.Name(), channel, envelopeService, transactionService, nw.TransactionManager(), vault)
 }
 
-func NewCommitter(nw fdriver.FabricNetworkService, channelConfig fdriver.ChannelConfig, vault fdriver.Vault, envelopeService fdriver.EnvelopeService, ledger fdriver.Ledger, rwsetLoaderService fdriver.RWSetLoader, eventsPublisher events.Publisher, channelMembershipService *membership.Service, fabricFinality fcommitter.FabricFinality, dependencyResolver fcommitter.DependencyResolver, quiet bool, listenerManager fdriver.ListenerManager, tracerProvider trace.TracerProvider, metricsProvider metrics.Provider) (*fcommitter.Committer, error) {
+func NewCommitter(nw fdriver.FabricNetworkService, channelConfig fdriver.ChannelConfig, vault fdriver.Vault, envelopeService fdriver.EnvelopeService, ledger fdriver.Ledger, rwsetLoaderService fdriver.RWSetLoader, eventsPublisher events.Publisher, channelMembershipService *membership.Service, fabricFinality fcommitter.FabricFinality, dependencyResolver fcommitter.DependencyResolver, quiet bool, listenerManager fdriver.ListenerManager, tracerProvider trace.TracerProvider, metricsProvider metrics.Provider, marshaller protoblocktx.Marshaller) (*fcommitter.Committer, error) {
 	os, ok := nw.OrderingService().(fcommitter.OrderingService)
 	if !ok {
 		return nil, errors.New("ordering service is not a committer.OrderingService")
@@ -199,7 +206,7 @@ func NewCommitter(nw fdriver.FabricNetworkService, channelConfig fdriver.Channel
 	}
 
 	// register fabricx transaction handler
-	committer2.RegisterTransactionHandler(c)
+	committer2.RegisterTransactionHandler(c, marshaller)
 	return c, nil
 }
 
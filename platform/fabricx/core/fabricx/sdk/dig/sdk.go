@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/finality"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/ledger"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/namespace"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/transaction/retry"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
 	"go.uber.org/dig"
 )
@@ -62,6 +63,7 @@ func (p *SDK) Install() error {
 		p.Container().Provide(ledger.NewBlockDispatcherProvider),
 		p.Container().Provide(finality.NewListenerManagerProvider),
 		p.Container().Provide(queryservice.NewProvider),
+		p.Container().Provide(retry.NewProvider),
 		p.Container().Provide(namespace.NewSubmitterFromFNS, dig.As(new(namespace.Submitter))),
 		p.Container().Provide(namespace.NewDeployerServiceFromFNS, dig.As(new(namespace.DeployerService))),
 		p.Container().Provide(protoblocktx2.NewStaticMappingService, dig.As(new(protoblocktx2.MappingService))),
@@ -104,9 +106,21 @@ func (p *SDK) Install() error {
 		digutils.Register[namespace.DeployerService](p.Container()),
 		digutils.Register[finality.ListenerManagerProvider](p.Container()),
 		digutils.Register[queryservice.Provider](p.Container()),
+		digutils.Register[retry.Provider](p.Container()),
 	)
 }
 
 func (p *SDK) Start(ctx context.Context) error {
-	return p.SDK.Start(ctx)
+	if err := p.SDK.Start(ctx); err != nil {
+		return err
+	}
+	if !p.FabricEnabled() {
+		return nil
+	}
+	// the connections to the query services are released when the node stops
+	return p.Container().Invoke(func(qsp queryservice.Provider) {
+		if r, ok := qsp.(*queryservice.RemoteQueryServiceProvider); ok {
+			r.Start(ctx)
+		}
+	})
 }
@@ -0,0 +1,266 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"encoding/asn1"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+)
+
+const (
+	// FirstValidAggregation uses the signatures of the first endorsement that verifies.
+	FirstValidAggregation = "firstValid"
+	// MultiSignatureAggregation lists the signatures of all endorsers for each namespace.
+	// The namespace policies must verify a MultiSignature rather than a single signature.
+	MultiSignatureAggregation = "multiSignature"
+	// ThresholdAggregation combines the signatures of the endorsers into a single threshold signature.
+	ThresholdAggregation = "threshold"
+)
+
+var ErrNoEndorsements = errors.New("no endorsements")
+
+// Endorsement is the per-namespace signatures produced by a single endorser.
+type Endorsement struct {
+	Endorser   view.Identity
+	Signatures [][]byte
+}
+
+// EndorsementAggregator combines the endorsements of a transaction into
+// the signatures stored in the fabricx transaction, one per namespace.
+type EndorsementAggregator interface {
+	Aggregate(tx protoblocktx.Tx, endorsements []Endorsement, verifierProvider VerifierProvider) ([][]byte, error)
+}
+
+// SignatureCombiner merges the signatures of several endorsers over the same message into one signature,
+// that verifies against the key of the namespace policy.
+type SignatureCombiner interface {
+	Combine(message []byte, endorsers []view.Identity, signatures [][]byte) ([]byte, error)
+}
+
+// NamedSignatureCombiner is a SignatureCombiner that the configuration refers to by name.
+type NamedSignatureCombiner struct {
+	Name string
+	SignatureCombiner
+}
+
+type AggregatorConfigService interface {
+	GetString(key string) string
+	GetInt(key string) int
+}
+
+// NewAggregatorFromConfig returns the aggregator named by the `endorsement.aggregation` key.
+// Threshold aggregation also reads the number of endorsements it requires from `endorsement.threshold`,
+// and the name of the combiner, one of the passed combiners, from `endorsement.combiner`.
+func NewAggregatorFromConfig(configService AggregatorConfigService, combiners []NamedSignatureCombiner) (EndorsementAggregator, error) {
+	name := configService.GetString("endorsement.aggregation")
+	if name != ThresholdAggregation {
+		return NewAggregator(name)
+	}
+
+	threshold := configService.GetInt("endorsement.threshold")
+	if threshold <= 0 {
+		return nil, errors.Errorf("invalid endorsement threshold [%d]", threshold)
+	}
+	combinerName := configService.GetString("endorsement.combiner")
+	for _, c := range combiners {
+		if c.Name == combinerName {
+			return NewThresholdAggregator(threshold, c.SignatureCombiner), nil
+		}
+	}
+	return nil, errors.Errorf("unknown signature combiner [%s]", combinerName)
+}
+
+// NewAggregator returns the aggregator registered under the passed name.
+// Threshold aggregation needs a SignatureCombiner and is built with NewThresholdAggregator instead.
+func NewAggregator(name string) (EndorsementAggregator, error) {
+	switch name {
+	case "", FirstValidAggregation:
+		return &firstValidAggregator{}, nil
+	case MultiSignatureAggregation:
+		return &multiSignatureAggregator{}, nil
+	case ThresholdAggregation:
+		return nil, errors.Errorf("endorsement aggregation [%s] requires a signature combiner", name)
+	default:
+		return nil, errors.Errorf("unknown endorsement aggregation [%s]", name)
+	}
+}
+
+// CollectEndorsements checks that all the passed proposal responses endorse the same payload
+// and returns the decoded transaction together with the endorsements.
+// Diverging payloads are reported with a DivergenceError.
+func CollectEndorsements(adapter protoblocktx.Marshaller, responses []*pb.ProposalResponse) (protoblocktx.Tx, []Endorsement, error) {
+	if len(responses) == 0 {
+		return nil, nil, ErrNoEndorsements
+	}
+
+	payload := responses[0].Payload
+	tx, err := adapter.UnmarshalTx(payload)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed unmarshalling endorsed payload")
+	}
+
+	endorsements := make([]Endorsement, len(responses))
+	var divergences []Divergence
+	for i, resp := range responses {
+		if resp.Endorsement == nil {
+			return nil, nil, errors.Errorf("proposal response [%d] has no endorsement", i)
+		}
+		endorser := view.Identity(resp.Endorsement.Endorser)
+		if !bytes.Equal(payload, resp.Payload) {
+			other, err := adapter.UnmarshalTx(resp.Payload)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed unmarshalling payload endorsed by [%s]", endorser)
+			}
+			diff := DiffTx(tx, other)
+			if len(diff) == 0 {
+				diff = []Divergence{{Kind: EncodingDivergence, Expected: payload, Actual: resp.Payload}}
+			}
+			for _, d := range diff {
+				d.Endorser = endorser
+				divergences = append(divergences, d)
+			}
+		}
+		sigs, err := UnmarshalEndorserSignatures(resp.Endorsement.Signature)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid endorsement from [%s]", endorser)
+		}
+		endorsements[i] = Endorsement{Endorser: endorser, Signatures: sigs}
+	}
+	if len(divergences) != 0 {
+		return nil, nil, &DivergenceError{TxID: tx.GetId(), Reference: responses[0].Endorsement.Endorser, Divergences: divergences}
+	}
+	for _, e := range endorsements {
+		if len(e.Signatures) != len(tx.GetNamespaces()) {
+			return nil, nil, errors.Errorf("expected [%d] signatures from [%s], got [%d]", len(tx.GetNamespaces()), e.Endorser, len(e.Signatures))
+		}
+	}
+	return tx, endorsements, nil
+}
+
+// verifyEndorsement checks the signatures of the endorsement against each namespace of the transaction.
+func verifyEndorsement(tx protoblocktx.Tx, e Endorsement, verifierProvider VerifierProvider) error {
+	v, err := verifierProvider.GetVerifier(e.Endorser)
+	if err != nil {
+		return errors.Wrapf(err, "failed getting verifier for [%s]", e.Endorser)
+	}
+	for i, ns := range tx.GetNamespaces() {
+		msg, err := HashTxNamespace(tx.GetId(), ns)
+		if err != nil {
+			return err
+		}
+		if err := v.Verify(msg, e.Signatures[i]); err != nil {
+			return errors.Wrapf(err, "invalid signature from [%s] for namespace [%s]", e.Endorser, ns.GetNsId())
+		}
+	}
+	return nil
+}
+
+type firstValidAggregator struct{}
+
+func (a *firstValidAggregator) Aggregate(tx protoblocktx.Tx, endorsements []Endorsement, verifierProvider VerifierProvider) ([][]byte, error) {
+	for _, e := range endorsements {
+		err := verifyEndorsement(tx, e, verifierProvider)
+		if err == nil {
+			return e.Signatures, nil
+		}
+		logger.Warnf("skip endorsement of [%s] for tx [%s]: %v", e.Endorser, tx.GetId(), err)
+	}
+	return nil, errors.Wrapf(ErrNoEndorsements, "no valid endorsement for tx [%s]", tx.GetId())
+}
+
+// MultiSignature lists the signatures of several endorsers over the same namespace.
+type MultiSignature struct {
+	Entries []MultiSignatureEntry
+}
+
+type MultiSignatureEntry struct {
+	Endorser  []byte
+	Signature []byte
+}
+
+func UnmarshalMultiSignature(raw []byte) (*MultiSignature, error) {
+	ms := &MultiSignature{}
+	if _, err := asn1.Unmarshal(raw, ms); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshalling multi signature")
+	}
+	return ms, nil
+}
+
+type multiSignatureAggregator struct{}
+
+func (a *multiSignatureAggregator) Aggregate(tx protoblocktx.Tx, endorsements []Endorsement, verifierProvider VerifierProvider) ([][]byte, error) {
+	for _, e := range endorsements {
+		if err := verifyEndorsement(tx, e, verifierProvider); err != nil {
+			return nil, err
+		}
+	}
+
+	sigs := make([][]byte, len(tx.GetNamespaces()))
+	for i := range tx.GetNamespaces() {
+		ms := MultiSignature{Entries: make([]MultiSignatureEntry, len(endorsements))}
+		for j, e := range endorsements {
+			ms.Entries[j] = MultiSignatureEntry{Endorser: e.Endorser, Signature: e.Signatures[i]}
+		}
+		raw, err := asn1.Marshal(ms)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed marshalling multi signature")
+		}
+		sigs[i] = raw
+	}
+	return sigs, nil
+}
+
+// NewThresholdAggregator returns an aggregator that requires at least threshold valid endorsements
+// and combines them with the passed combiner.
+func NewThresholdAggregator(threshold int, combiner SignatureCombiner) EndorsementAggregator {
+	return &thresholdAggregator{threshold: threshold, combiner: combiner}
+}
+
+type thresholdAggregator struct {
+	threshold int
+	combiner  SignatureCombiner
+}
+
+func (a *thresholdAggregator) Aggregate(tx protoblocktx.Tx, endorsements []Endorsement, verifierProvider VerifierProvider) ([][]byte, error) {
+	valid := make([]Endorsement, 0, len(endorsements))
+	for _, e := range endorsements {
+		if err := verifyEndorsement(tx, e, verifierProvider); err != nil {
+			logger.Warnf("skip endorsement of [%s] for tx [%s]: %v", e.Endorser, tx.GetId(), err)
+			continue
+		}
+		valid = append(valid, e)
+	}
+	if len(valid) < a.threshold {
+		return nil, errors.Errorf("not enough valid endorsements for tx [%s], expected [%d], got [%d]", tx.GetId(), a.threshold, len(valid))
+	}
+
+	endorsers := make([]view.Identity, len(valid))
+	for j, e := range valid {
+		endorsers[j] = e.Endorser
+	}
+	sigs := make([][]byte, len(tx.GetNamespaces()))
+	for i, ns := range tx.GetNamespaces() {
+		nsSigs := make([][]byte, len(valid))
+		for j, e := range valid {
+			nsSigs[j] = e.Signatures[i]
+		}
+		msg, err := HashTxNamespace(tx.GetId(), ns)
+		if err != nil {
+			return nil, err
+		}
+		sigs[i], err = a.combiner.Combine(msg, endorsers, nsSigs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed combining signatures for namespace [%s]", ns.GetNsId())
+		}
+	}
+	return sigs, nil
+}
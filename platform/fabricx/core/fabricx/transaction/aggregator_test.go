@@ -0,0 +1,186 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixSigner signs by prefixing the message with the identity, prefixVerifier checks that.
+type prefixSigner struct{ id []byte }
+
+func (s prefixSigner) Sign(message []byte) ([]byte, error) {
+	return append(bytes.Clone(s.id), message...), nil
+}
+
+type prefixVerifier struct{ id []byte }
+
+func (v prefixVerifier) Verify(message, sigma []byte) error {
+	if !bytes.Equal(sigma, append(bytes.Clone(v.id), message...)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+type prefixVerifierProvider struct{}
+
+func (prefixVerifierProvider) GetVerifier(identity view.Identity) (driver.Verifier, error) {
+	return prefixVerifier{id: identity}, nil
+}
+
+type concatCombiner struct{}
+
+func (concatCombiner) Combine(_ []byte, _ []view.Identity, signatures [][]byte) ([]byte, error) {
+	return bytes.Join(signatures, nil), nil
+}
+
+// endorsementConfig serves the endorsement settings of a network
+type endorsementConfig map[string]any
+
+func (c endorsementConfig) GetString(key string) string {
+	s, _ := c[key].(string)
+	return s
+}
+
+func (c endorsementConfig) GetInt(key string) int {
+	i, _ := c[key].(int)
+	return i
+}
+
+func testTx() protoblocktx.Tx {
+	return protoblocktx.NewTx("tx1", []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k1"), []byte("v1"))}),
+		protoblocktx.NewTxNamespace("ns2", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k2"), []byte("v2"))}),
+	}, nil)
+}
+
+func endorse(t *testing.T, endorser string, payload []byte, tx protoblocktx.Tx) *pb.ProposalResponse {
+	t.Helper()
+	sigs, err := SignTxNamespaces(prefixSigner{id: []byte(endorser)}, tx)
+	require.NoError(t, err)
+	sig, err := MarshalEndorserSignatures(sigs)
+	require.NoError(t, err)
+	return &pb.ProposalResponse{Payload: payload, Endorsement: &pb.Endorsement{Endorser: []byte(endorser), Signature: sig}}
+}
+
+func TestAggregators(t *testing.T) {
+	adapter := v2.NewMarshallerAdapter()
+	tx := testTx()
+	payload, err := adapter.MarshalTx(tx)
+	require.NoError(t, err)
+
+	alice := endorse(t, "alice", payload, tx)
+	bob := endorse(t, "bob", payload, tx)
+	forged := endorse(t, "eve", payload, tx)
+	forged.Endorsement.Endorser = []byte("mallory")
+
+	t.Run("collect rejects diverging payloads", func(t *testing.T) {
+		other, err := adapter.MarshalTx(protoblocktx.NewTx("tx2", tx.GetNamespaces(), nil))
+		require.NoError(t, err)
+		_, _, err = CollectEndorsements(adapter, []*pb.ProposalResponse{alice, endorse(t, "bob", other, tx)})
+		require.Error(t, err)
+
+		_, _, err = CollectEndorsements(adapter, nil)
+		require.ErrorIs(t, err, ErrNoEndorsements)
+	})
+
+	t.Run("first valid", func(t *testing.T) {
+		decoded, endorsements, err := CollectEndorsements(adapter, []*pb.ProposalResponse{forged, bob, alice})
+		require.NoError(t, err)
+		a, err := NewAggregator(FirstValidAggregation)
+		require.NoError(t, err)
+		sigs, err := a.Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.NoError(t, err)
+		require.Equal(t, endorsements[1].Signatures, sigs)
+
+		_, err = a.Aggregate(decoded, endorsements[:1], prefixVerifierProvider{})
+		require.ErrorIs(t, err, ErrNoEndorsements)
+	})
+
+	t.Run("multi signature", func(t *testing.T) {
+		decoded, endorsements, err := CollectEndorsements(adapter, []*pb.ProposalResponse{alice, bob})
+		require.NoError(t, err)
+		a, err := NewAggregator(MultiSignatureAggregation)
+		require.NoError(t, err)
+		sigs, err := a.Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.NoError(t, err)
+		require.Len(t, sigs, 2)
+		for i, sig := range sigs {
+			ms, err := UnmarshalMultiSignature(sig)
+			require.NoError(t, err)
+			require.Len(t, ms.Entries, 2)
+			require.Equal(t, []byte("alice"), ms.Entries[0].Endorser)
+			require.Equal(t, endorsements[0].Signatures[i], ms.Entries[0].Signature)
+			require.Equal(t, []byte("bob"), ms.Entries[1].Endorser)
+			require.Equal(t, endorsements[1].Signatures[i], ms.Entries[1].Signature)
+		}
+
+		_, endorsements, err = CollectEndorsements(adapter, []*pb.ProposalResponse{alice, forged})
+		require.NoError(t, err)
+		_, err = a.Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.Error(t, err)
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		decoded, endorsements, err := CollectEndorsements(adapter, []*pb.ProposalResponse{alice, forged, bob})
+		require.NoError(t, err)
+		sigs, err := NewThresholdAggregator(2, concatCombiner{}).Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.NoError(t, err)
+		require.Len(t, sigs, 2)
+		require.Equal(t, append(bytes.Clone(endorsements[0].Signatures[0]), endorsements[2].Signatures[0]...), sigs[0])
+
+		_, err = NewThresholdAggregator(3, concatCombiner{}).Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.Error(t, err)
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		combiners := []NamedSignatureCombiner{{Name: "concat", SignatureCombiner: concatCombiner{}}}
+		decoded, endorsements, err := CollectEndorsements(adapter, []*pb.ProposalResponse{alice, forged, bob})
+		require.NoError(t, err)
+
+		a, err := NewAggregatorFromConfig(endorsementConfig{}, nil)
+		require.NoError(t, err)
+		require.IsType(t, &firstValidAggregator{}, a)
+		a, err = NewAggregatorFromConfig(endorsementConfig{"endorsement.aggregation": MultiSignatureAggregation}, nil)
+		require.NoError(t, err)
+		require.IsType(t, &multiSignatureAggregator{}, a)
+
+		a, err = NewAggregatorFromConfig(endorsementConfig{
+			"endorsement.aggregation": ThresholdAggregation,
+			"endorsement.threshold":   2,
+			"endorsement.combiner":    "concat",
+		}, combiners)
+		require.NoError(t, err)
+		sigs, err := a.Aggregate(decoded, endorsements, prefixVerifierProvider{})
+		require.NoError(t, err)
+		require.Equal(t, append(bytes.Clone(endorsements[0].Signatures[0]), endorsements[2].Signatures[0]...), sigs[0])
+
+		// the threshold and the combiner must be set
+		_, err = NewAggregatorFromConfig(endorsementConfig{"endorsement.aggregation": ThresholdAggregation, "endorsement.combiner": "concat"}, combiners)
+		require.EqualError(t, err, "invalid endorsement threshold [0]")
+		_, err = NewAggregatorFromConfig(endorsementConfig{"endorsement.aggregation": ThresholdAggregation, "endorsement.threshold": 2, "endorsement.combiner": "bls"}, combiners)
+		require.EqualError(t, err, "unknown signature combiner [bls]")
+	})
+
+	t.Run("unknown aggregation", func(t *testing.T) {
+		_, err := NewAggregator("unknown")
+		require.Error(t, err)
+
+		// threshold aggregation needs a combiner
+		_, err = NewAggregator(ThresholdAggregation)
+		require.Error(t, err)
+	})
+}
@@ -8,27 +8,70 @@ package transaction
 
 import (
 	"encoding/asn1"
+	"fmt"
 
 	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/hash"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
 )
 
-func HashTxNamespace(txID string, ns protoblocktx.TxNamespace) []byte {
+// ErrHashTxNamespace is returned when the namespace of a transaction cannot be hashed for signing.
+var ErrHashTxNamespace = errors.New("cannot hash transaction namespace")
+
+func HashTxNamespace(txID string, ns protoblocktx.TxNamespace) ([]byte, error) {
 	bytes, err := asn1.Marshal(Tx{
 		TxID:      txID,
 		Namespace: hashTxNamespace(ns),
 	})
 	if err != nil {
-		// TODO better way to deal with an error here
-		panic(errors.Wrap(err, "cannot hash transaction due to marshaling error"))
+		return nil, fmt.Errorf("failed marshalling namespace [%s] of [%s]: %w: %w", ns.GetNsId(), txID, err, ErrHashTxNamespace)
 	}
 
 	h, err := hash.SHA256(bytes)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed hashing namespace [%s] of [%s]: %w: %w", ns.GetNsId(), txID, err, ErrHashTxNamespace)
 	}
-	return h
+	return h, nil
+}
+
+// SignTxNamespaces signs every namespace of the given transaction and returns
+// the signatures in the same order as the namespaces.
+func SignTxNamespaces(signer Signer, tx protoblocktx.Tx) ([][]byte, error) {
+	sigs := make([][]byte, len(tx.GetNamespaces()))
+	for i, ns := range tx.GetNamespaces() {
+		msg, err := HashTxNamespace(tx.GetId(), ns)
+		if err != nil {
+			return nil, err
+		}
+		sigs[i], err = signer.Sign(msg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed signing namespace [%s]", ns.GetNsId())
+		}
+	}
+	return sigs, nil
+}
+
+// MarshalEndorserSignatures encodes the per-namespace signatures of an endorser
+// so that they fit in the single signature slot of a proposal response endorsement.
+func MarshalEndorserSignatures(sigs [][]byte) ([]byte, error) {
+	raw, err := asn1.Marshal(EndorserSignatures{Signatures: sigs})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed marshalling endorser signatures")
+	}
+	return raw, nil
+}
+
+// UnmarshalEndorserSignatures decodes the output of MarshalEndorserSignatures.
+func UnmarshalEndorserSignatures(raw []byte) ([][]byte, error) {
+	var s EndorserSignatures
+	rest, err := asn1.Unmarshal(raw, &s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed unmarshalling endorser signatures")
+	}
+	if len(rest) != 0 {
+		return nil, errors.Errorf("trailing data after endorser signatures [%d bytes]", len(rest))
+	}
+	return s.Signatures, nil
 }
 
 func hashTxNamespace(ns protoblocktx.TxNamespace) Namespace {
@@ -63,6 +106,10 @@ func hashTxNamespace(ns protoblocktx.TxNamespace) Namespace {
 	return n
 }
 
+type EndorserSignatures struct {
+	Signatures [][]byte
+}
+
 type Tx struct {
 	TxID      string
 	Namespace Namespace
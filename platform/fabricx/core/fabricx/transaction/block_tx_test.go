@@ -0,0 +1,66 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+type hashSigner struct{}
+
+func (hashSigner) Sign(message []byte) ([]byte, error) { return bytes.Clone(message), nil }
+
+func mustHash(t *testing.T, txID string, ns protoblocktx.TxNamespace) []byte {
+	t.Helper()
+	h, err := HashTxNamespace(txID, ns)
+	require.NoError(t, err)
+	return h
+}
+
+func TestSignTxNamespaces(t *testing.T) {
+	tx := protoblocktx.NewTx("tx1", []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k1"), []byte("v1"))}),
+		protoblocktx.NewTxNamespace("ns2", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k2"), []byte("v2"))}),
+	}, nil)
+
+	sigs, err := SignTxNamespaces(hashSigner{}, tx)
+	require.NoError(t, err)
+	require.Len(t, sigs, 2)
+	require.Equal(t, mustHash(t, "tx1", tx.GetNamespaces()[0]), sigs[0])
+	require.Equal(t, mustHash(t, "tx1", tx.GetNamespaces()[1]), sigs[1])
+	require.NotEqual(t, sigs[0], sigs[1])
+}
+
+func TestEndorserSignatures(t *testing.T) {
+	sigs := [][]byte{[]byte("sig1"), []byte("sig2"), []byte("sig3")}
+
+	raw, err := MarshalEndorserSignatures(sigs)
+	require.NoError(t, err)
+
+	res, err := UnmarshalEndorserSignatures(raw)
+	require.NoError(t, err)
+	require.Equal(t, sigs, res)
+
+	_, err = UnmarshalEndorserSignatures([]byte("not asn1"))
+	require.Error(t, err)
+
+	_, err = UnmarshalEndorserSignatures(append(raw, 0))
+	require.Error(t, err)
+
+	// the signatures are only reachable one per namespace
+	pr, err := NewProposalResponseFromResponse(&peer.ProposalResponse{Endorsement: &peer.Endorsement{Signature: raw}}, nil)
+	require.NoError(t, err)
+	require.Nil(t, pr.EndorserSignature())
+	res, err = pr.EndorserSignatures()
+	require.NoError(t, err)
+	require.Equal(t, sigs, res)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+)
+
+// DivergenceKind tells in which respect an endorsed payload differs from the reference one.
+type DivergenceKind string
+
+const (
+	// TxIDDivergence means the endorsers endorsed different transactions.
+	TxIDDivergence DivergenceKind = "txID"
+	// MissingNamespace means that a namespace is in one payload only.
+	MissingNamespace DivergenceKind = "missingNamespace"
+	// NamespaceVersionDivergence means that the namespace has a different version.
+	NamespaceVersionDivergence DivergenceKind = "namespaceVersion"
+	// MissingKey means that a key is read or written in one payload only.
+	MissingKey DivergenceKind = "missingKey"
+	// ReadVersionDivergence means that a key is read at a different version, or read in one payload only.
+	ReadVersionDivergence DivergenceKind = "readVersion"
+	// ValueDivergence means that a key is written with a different value, or written in one payload only.
+	ValueDivergence DivergenceKind = "value"
+	// EncodingDivergence means that the payloads hold the same reads and writes in a different order.
+	EncodingDivergence DivergenceKind = "encoding"
+)
+
+// Divergence is a single difference between the payload of an endorser and the reference payload.
+// Expected and Actual are nil when the entry is missing on that side.
+type Divergence struct {
+	Endorser  view.Identity
+	Namespace driver.Namespace
+	Key       string
+	Kind      DivergenceKind
+	Expected  []byte
+	Actual    []byte
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("[%s] %s [%s:%s]: expected [%x], got [%x]", d.Endorser, d.Kind, d.Namespace, d.Key, d.Expected, d.Actual)
+}
+
+// DivergenceError is returned when the endorsers of a transaction did not endorse the same payload.
+// The reference payload is the one of the first endorser.
+type DivergenceError struct {
+	TxID        string
+	Reference   view.Identity
+	Divergences []Divergence
+}
+
+func (e *DivergenceError) Error() string {
+	entries := make([]string, len(e.Divergences))
+	for i, d := range e.Divergences {
+		entries[i] = d.String()
+	}
+	return fmt.Sprintf("endorsements of tx [%s] diverge from the one of [%s]: %s", e.TxID, e.Reference, strings.Join(entries, "; "))
+}
+
+// DiffTx compares the reads and writes of actual against expected, namespace by namespace.
+// The endorser of the returned divergences is left empty.
+func DiffTx(expected, actual protoblocktx.Tx) []Divergence {
+	var divergences []Divergence
+	if expected.GetId() != actual.GetId() {
+		divergences = append(divergences, Divergence{Kind: TxIDDivergence, Expected: []byte(expected.GetId()), Actual: []byte(actual.GetId())})
+	}
+
+	actualNss := make(map[driver.Namespace]protoblocktx.TxNamespace, len(actual.GetNamespaces()))
+	for _, ns := range actual.GetNamespaces() {
+		actualNss[ns.GetNsId()] = ns
+	}
+	for _, ens := range expected.GetNamespaces() {
+		ans, ok := actualNss[ens.GetNsId()]
+		if !ok {
+			divergences = append(divergences, Divergence{Namespace: ens.GetNsId(), Kind: MissingNamespace, Expected: []byte(ens.GetNsId())})
+			continue
+		}
+		delete(actualNss, ens.GetNsId())
+		divergences = append(divergences, diffNamespace(ens, ans)...)
+	}
+	for _, ans := range actual.GetNamespaces() {
+		if _, ok := actualNss[ans.GetNsId()]; ok {
+			divergences = append(divergences, Divergence{Namespace: ans.GetNsId(), Kind: MissingNamespace, Actual: []byte(ans.GetNsId())})
+		}
+	}
+	return divergences
+}
+
+type keyAccess struct {
+	read    bool
+	version []byte
+	write   bool
+	value   []byte
+}
+
+func namespaceAccesses(ns protoblocktx.TxNamespace) ([]string, map[string]*keyAccess) {
+	keys := make([]string, 0)
+	accesses := make(map[string]*keyAccess)
+	access := func(key []byte) *keyAccess {
+		a, ok := accesses[string(key)]
+		if !ok {
+			a = &keyAccess{}
+			accesses[string(key)] = a
+			keys = append(keys, string(key))
+		}
+		return a
+	}
+	for _, r := range ns.GetReadsOnly() {
+		a := access(r.GetKey())
+		a.read, a.version = true, r.GetVersion()
+	}
+	for _, rw := range ns.GetReadWrites() {
+		a := access(rw.GetKey())
+		a.read, a.version = true, rw.GetVersion()
+		a.write, a.value = true, rw.GetValue()
+	}
+	for _, w := range ns.GetBlindWrites() {
+		a := access(w.GetKey())
+		a.write, a.value = true, w.GetValue()
+	}
+	return keys, accesses
+}
+
+func diffNamespace(expected, actual protoblocktx.TxNamespace) []Divergence {
+	ns := expected.GetNsId()
+	var divergences []Divergence
+	if !bytes.Equal(expected.GetNsVersion(), actual.GetNsVersion()) {
+		divergences = append(divergences, Divergence{Namespace: ns, Kind: NamespaceVersionDivergence, Expected: expected.GetNsVersion(), Actual: actual.GetNsVersion()})
+	}
+
+	expectedKeys, expectedAccesses := namespaceAccesses(expected)
+	actualKeys, actualAccesses := namespaceAccesses(actual)
+	for _, key := range expectedKeys {
+		e := expectedAccesses[key]
+		a, ok := actualAccesses[key]
+		if !ok {
+			divergences = append(divergences, Divergence{Namespace: ns, Key: key, Kind: MissingKey, Expected: []byte(key)})
+			continue
+		}
+		if e.read != a.read || !bytes.Equal(e.version, a.version) {
+			divergences = append(divergences, Divergence{Namespace: ns, Key: key, Kind: ReadVersionDivergence, Expected: e.version, Actual: a.version})
+		}
+		if e.write != a.write || !bytes.Equal(e.value, a.value) {
+			divergences = append(divergences, Divergence{Namespace: ns, Key: key, Kind: ValueDivergence, Expected: e.value, Actual: a.value})
+		}
+	}
+	for _, key := range actualKeys {
+		if _, ok := expectedAccesses[key]; !ok {
+			divergences = append(divergences, Divergence{Namespace: ns, Key: key, Kind: MissingKey, Actual: []byte(key)})
+		}
+	}
+	return divergences
+}
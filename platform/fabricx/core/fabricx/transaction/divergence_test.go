@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTx(t *testing.T) {
+	expected := protoblocktx.NewTx("tx1", []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", []byte("0"),
+			[]protoblocktx.Read{protoblocktx.NewRead([]byte("r1"), []byte("1")), protoblocktx.NewRead([]byte("r2"), nil)},
+			[]protoblocktx.ReadWrite{protoblocktx.NewReadWrite([]byte("rw1"), []byte("1"), []byte("a"))},
+			[]protoblocktx.Write{protoblocktx.NewWrite([]byte("w1"), []byte("b"))},
+		),
+		protoblocktx.NewTxNamespace("ns2", nil, nil, nil, nil),
+	}, nil)
+	require.Empty(t, DiffTx(expected, expected))
+
+	actual := protoblocktx.NewTx("tx1", []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", []byte("1"),
+			[]protoblocktx.Read{protoblocktx.NewRead([]byte("r1"), []byte("2"))},
+			[]protoblocktx.ReadWrite{protoblocktx.NewReadWrite([]byte("rw1"), []byte("1"), []byte("c"))},
+			[]protoblocktx.Write{protoblocktx.NewWrite([]byte("w1"), []byte("b")), protoblocktx.NewWrite([]byte("w2"), []byte("d"))},
+		),
+		protoblocktx.NewTxNamespace("ns3", nil, nil, nil, nil),
+	}, nil)
+	require.Equal(t, []Divergence{
+		{Namespace: "ns1", Kind: NamespaceVersionDivergence, Expected: []byte("0"), Actual: []byte("1")},
+		{Namespace: "ns1", Key: "r1", Kind: ReadVersionDivergence, Expected: []byte("1"), Actual: []byte("2")},
+		{Namespace: "ns1", Key: "r2", Kind: MissingKey, Expected: []byte("r2")},
+		{Namespace: "ns1", Key: "rw1", Kind: ValueDivergence, Expected: []byte("a"), Actual: []byte("c")},
+		{Namespace: "ns1", Key: "w2", Kind: MissingKey, Actual: []byte("w2")},
+		{Namespace: "ns2", Kind: MissingNamespace, Expected: []byte("ns2")},
+		{Namespace: "ns3", Kind: MissingNamespace, Actual: []byte("ns3")},
+	}, DiffTx(expected, actual))
+}
+
+func TestCollectDivergingEndorsements(t *testing.T) {
+	adapter := v2.NewMarshallerAdapter()
+	tx := testTx()
+	payload, err := adapter.MarshalTx(tx)
+	require.NoError(t, err)
+
+	other := protoblocktx.NewTx(tx.GetId(), []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k1"), []byte("v3"))}),
+		tx.GetNamespaces()[1],
+	}, nil)
+	otherPayload, err := adapter.MarshalTx(other)
+	require.NoError(t, err)
+
+	_, _, err = CollectEndorsements(adapter, []*pb.ProposalResponse{endorse(t, "alice", payload, tx), endorse(t, "bob", otherPayload, other)})
+	var divergenceErr *DivergenceError
+	require.True(t, errors.As(err, &divergenceErr))
+	require.Equal(t, "tx1", divergenceErr.TxID)
+	require.Equal(t, []byte("alice"), []byte(divergenceErr.Reference))
+	require.Equal(t, []Divergence{
+		{Endorser: []byte("bob"), Namespace: "ns1", Key: "k1", Kind: ValueDivergence, Expected: []byte("v1"), Actual: []byte("v3")},
+	}, divergenceErr.Divergences)
+}
@@ -84,6 +84,9 @@ func (e *Envelope) Envelope() *common.Envelope {
 
 type UnpackedEnvelope struct {
 	TxID     string
+	Channel  string
+	Creator  []byte
+	Nonce    []byte
 	Results  []byte
 	Envelope []byte
 }
@@ -134,8 +137,16 @@ func UnpackEnvelopePayload(payloadRaw []byte) (*UnpackedEnvelope, int32, error)
 		return nil, chdr.Type, errors.Errorf("only HeaderType_MESSAGE Transactions are supported, provided type %d", chdr.Type)
 	}
 
+	shdr, err := protoutil.UnmarshalSignatureHeader(payl.Header.SignatureHeader)
+	if err != nil {
+		return nil, chdr.Type, errors.Wrap(err, "failed to unmarshal signature header")
+	}
+
 	return &UnpackedEnvelope{
 		TxID:    chdr.TxId,
+		Channel: chdr.ChannelId,
+		Creator: shdr.Creator,
+		Nonce:   shdr.Nonce,
 		Results: payl.Data,
 	}, chdr.Type, nil
 }
@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpackEnvelopeFromBytes(t *testing.T) {
+	chdr := protoutil.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "mychannel", 0)
+	chdr.TxId = "tx1"
+	shdr := &common.SignatureHeader{Creator: []byte("alice"), Nonce: []byte("nonce")}
+	payload := &common.Payload{Header: protoutil.MakePayloadHeader(chdr, shdr), Data: []byte("data")}
+	env := &common.Envelope{Payload: protoutil.MarshalOrPanic(payload)}
+	raw, err := proto.Marshal(env)
+	require.NoError(t, err)
+
+	upe, headerType, err := UnpackEnvelopeFromBytes(raw)
+	require.NoError(t, err)
+	require.Equal(t, int32(common.HeaderType_MESSAGE), headerType)
+	require.Equal(t, "tx1", upe.TxID)
+	require.Equal(t, "mychannel", upe.Channel)
+	require.Equal(t, []byte("alice"), upe.Creator)
+	require.Equal(t, []byte("nonce"), upe.Nonce)
+	require.Equal(t, []byte("data"), upe.Results)
+
+	chdr.Type = int32(common.HeaderType_ENDORSER_TRANSACTION)
+	payload.Header = protoutil.MakePayloadHeader(chdr, shdr)
+	raw, err = proto.Marshal(&common.Envelope{Payload: protoutil.MarshalOrPanic(payload)})
+	require.NoError(t, err)
+	_, headerType, err = UnpackEnvelopeFromBytes(raw)
+	require.Error(t, err)
+	require.Equal(t, int32(common.HeaderType_ENDORSER_TRANSACTION), headerType)
+}
+
+// envelopeFNS returns the channels of the envelopes under test, verifying the signatures of prefixSigner
+type envelopeFNS struct{ driver.FabricNetworkService }
+
+func (envelopeFNS) Name() string { return "network" }
+
+func (envelopeFNS) Channel(string) (driver.Channel, error) { return envelopeChannel{}, nil }
+
+type envelopeChannel struct{ driver.Channel }
+
+func (envelopeChannel) ChannelMembership() driver.ChannelMembership { return prefixMembership{} }
+
+type prefixMembership struct{ driver.ChannelMembership }
+
+func (prefixMembership) GetVerifier(identity view.Identity) (driver.Verifier, error) {
+	return prefixVerifierProvider{}.GetVerifier(identity)
+}
+
+// envelopePayload returns the payload of the transaction created by alice
+func envelopePayload(txID string, results []byte) *common.Payload {
+	chdr := protoutil.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "mychannel", 0)
+	chdr.TxId = txID
+	shdr := &common.SignatureHeader{Creator: []byte("alice"), Nonce: []byte("nonce")}
+	return &common.Payload{Header: protoutil.MakePayloadHeader(chdr, shdr), Data: results}
+}
+
+// envelopeBytes returns the envelope of the payload, signed by alice
+func envelopeBytes(t *testing.T, payload *common.Payload) []byte {
+	t.Helper()
+	raw := protoutil.MarshalOrPanic(payload)
+	signature, err := prefixSigner{id: []byte("alice")}.Sign(raw)
+	require.NoError(t, err)
+	envRaw, err := proto.Marshal(&common.Envelope{Payload: raw, Signature: signature})
+	require.NoError(t, err)
+	return envRaw
+}
+
+func TestTransactionFromEnvelopeBytes(t *testing.T) {
+	adapter := v2.NewMarshallerAdapter()
+	txID := protoutil.ComputeTxID([]byte("nonce"), []byte("alice"))
+	tx := protoblocktx.NewTx(txID, testTx().GetNamespaces(), nil)
+	rwset, err := adapter.MarshalTx(tx)
+	require.NoError(t, err)
+	sigs, err := SignTxNamespaces(prefixSigner{id: []byte("bob")}, tx)
+	require.NoError(t, err)
+	results, err := adapter.MarshalTx(protoblocktx.NewTx(txID, tx.GetNamespaces(), sigs))
+	require.NoError(t, err)
+	raw := envelopeBytes(t, envelopePayload(txID, results))
+
+	mapper, err := NewStaticNamespaceMapper()
+	require.NoError(t, err)
+	m := NewManager(adapter)
+	m.AddTransactionFactory(driver.EndorserTransaction, NewTransactionFactory(envelopeFNS{}, adapter, nil, "", mapper))
+
+	t.Run("manager", func(t *testing.T) {
+		loaded, err := m.NewTransactionFromEnvelopeBytes(context.Background(), "mychannel", raw)
+		require.NoError(t, err)
+		require.Equal(t, txID, loaded.ID())
+		require.Equal(t, "mychannel", loaded.Channel())
+		require.Equal(t, view.Identity("alice"), loaded.Creator())
+		require.Equal(t, []byte("nonce"), loaded.Nonce())
+		results, err := loaded.Results()
+		require.NoError(t, err)
+		require.Equal(t, rwset, results)
+
+		// the envelope is submitted as loaded
+		env, err := loaded.Envelope()
+		require.NoError(t, err)
+		envRaw, err := env.Bytes()
+		require.NoError(t, err)
+		require.Equal(t, raw, envRaw)
+
+		// the envelope belongs to another channel than the requested one
+		_, err = m.NewTransactionFromEnvelopeBytes(context.Background(), "otherchannel", raw)
+		require.ErrorIs(t, err, ErrChannelMismatch)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		loaded := &Transaction{fns: envelopeFNS{}, adapter: adapter}
+		require.NoError(t, loaded.SetFromEnvelopeBytes(raw))
+		require.Equal(t, txID, loaded.ID())
+		require.Equal(t, rwset, loaded.RWSet)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := m.NewTransactionFromEnvelopeBytes(context.Background(), "mychannel", []byte("not an envelope"))
+		require.Error(t, err)
+
+		// the payload carries another tx id than the header
+		err = (&Transaction{fns: envelopeFNS{}, adapter: adapter}).SetFromEnvelopeBytes(envelopeBytes(t, envelopePayload("tx1", results)))
+		require.ErrorIs(t, err, ErrTxIDMismatch)
+
+		// the namespaces are not signed
+		err = (&Transaction{fns: envelopeFNS{}, adapter: adapter}).SetFromEnvelopeBytes(envelopeBytes(t, envelopePayload(txID, rwset)))
+		require.ErrorIs(t, err, ErrRWSetMismatch)
+
+		// the envelope is not signed by its creator
+		env := &common.Envelope{}
+		require.NoError(t, proto.Unmarshal(raw, env))
+		env.Signature = []byte("forged")
+		forged, err := proto.Marshal(env)
+		require.NoError(t, err)
+		_, err = m.NewTransactionFromEnvelopeBytes(context.Background(), "mychannel", forged)
+		require.ErrorIs(t, err, ErrInvalidCreatorSignature)
+	})
+}
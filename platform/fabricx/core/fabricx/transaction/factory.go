@@ -16,13 +16,22 @@ import (
 	"github.com/hyperledger/fabric/protoutil"
 )
 
+// defaultNamespace and defaultNamespaceVersion bind the new transactions of the channels without a default mapping
+const (
+	defaultNamespace        = "iou"
+	defaultNamespaceVersion = "1"
+)
+
 type TransactionFactory struct {
-	fns     driver.FabricNetworkService
-	adapter protoblocktx.Marshaller
+	fns        driver.FabricNetworkService
+	adapter    protoblocktx.Marshaller
+	aggregator EndorsementAggregator
+	encoding   string
+	mapper     NamespaceMapper
 }
 
-func NewTransactionFactory(fns driver.FabricNetworkService, adapter protoblocktx.Marshaller) *TransactionFactory {
-	return &TransactionFactory{fns: fns, adapter: adapter}
+func NewTransactionFactory(fns driver.FabricNetworkService, adapter protoblocktx.Marshaller, aggregator EndorsementAggregator, encoding string, mapper NamespaceMapper) *TransactionFactory {
+	return &TransactionFactory{fns: fns, adapter: adapter, aggregator: aggregator, encoding: encoding, mapper: mapper}
 }
 
 func (e *TransactionFactory) NewTransaction(ctx context.Context, channelName string, nonce, creator []byte, txID driver2.TxID, rawRequest []byte) (driver.Transaction, error) {
@@ -41,20 +50,30 @@ func (e *TransactionFactory) NewTransaction(ctx context.Context, channelName str
 		txID = protoutil.ComputeTxID(nonce, creator)
 	}
 
+	// new transactions are bound to the default namespace of the channel until a proposal is set
+	namespace, nsVersion := defaultNamespace, defaultNamespaceVersion
+	if e.mapper != nil {
+		if ns, version := e.mapper.Map(channelName, "", "", ""); len(ns) != 0 {
+			namespace, nsVersion = ns, version
+		}
+	}
+
 	return &Transaction{
-		ctx:        ctx,
-		fns:        e.fns,
-		adapter:    e.adapter,
-		channel:    ch,
-		TCreator:   creator,
-		TNonce:     nonce,
-		TTxID:      txID,
-		TNetwork:   e.fns.Name(),
-		TChannel:   channelName,
-		TTransient: map[string][]byte{},
-		// TODO: we need the correct values here
-		// TODO: we need a mapper that link between classic strings to new sc namespace
-		TChaincodeVersion: "1",
-		TChaincode:        "iou",
+		ctx:               ctx,
+		fns:               e.fns,
+		adapter:           e.adapter,
+		aggregator:        e.aggregator,
+		encoding:          e.encoding,
+		mapper:            e.mapper,
+		channel:           ch,
+		TType:             driver.EndorserTransaction,
+		TCreator:          creator,
+		TNonce:            nonce,
+		TTxID:             txID,
+		TNetwork:          e.fns.Name(),
+		TChannel:          channelName,
+		TTransient:        map[string][]byte{},
+		TChaincode:        namespace,
+		TChaincodeVersion: nsVersion,
 	}, nil
 }
@@ -67,8 +67,11 @@ func (m *Manager) NewTransaction(ctx context.Context, transactionType driver.Tra
 }
 
 func (m *Manager) NewTransactionFromBytes(ctx context.Context, channel string, raw []byte) (driver.Transaction, error) {
-	// TODO: remove fixed transaction type
-	txFactory, err := m.transactionFactory(driver.EndorserTransaction)
+	transactionType, err := TransactionTypeFromBytes(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting transaction type")
+	}
+	txFactory, err := m.transactionFactory(transactionType)
 	if err != nil {
 		return nil, err
 	}
@@ -85,9 +88,26 @@ func (m *Manager) NewTransactionFromBytes(ctx context.Context, channel string, r
 	return tx, nil
 }
 
-func (m *Manager) NewTransactionFromEnvelopeBytes(context.Context, string, []byte) (driver.Transaction, error) {
-	// TODO: implement me
-	panic("NewTransactionFromEnvelopeBytes >> implement me")
+func (m *Manager) NewTransactionFromEnvelopeBytes(ctx context.Context, channel string, raw []byte) (driver.Transaction, error) {
+	// fabricx envelopes only carry endorser transactions
+	txFactory, err := m.transactionFactory(driver.EndorserTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := txFactory.NewTransaction(ctx, channel, nil, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.SetFromEnvelopeBytes(raw); err != nil {
+		return nil, err
+	}
+	if tx.Channel() != channel {
+		return nil, errors.Wrapf(ErrChannelMismatch, "expected [%s], envelope [%s]", channel, tx.Channel())
+	}
+
+	return tx, nil
 }
 
 func (m *Manager) AddTransactionFactory(transactionType driver.TransactionType, factory driver.TransactionFactory) {
@@ -103,13 +123,16 @@ func (m *Manager) NewProcessedTransactionFromEnvelopeRaw(envelope []byte) (drive
 }
 
 func (m *Manager) NewProcessedTransaction(pt []byte) (driver.ProcessedTransaction, error) {
-	return NewProcessedTransaction(pt)
+	return NewProcessedTransaction(pt, m.adapter)
 }
 
 type processedTransaction struct {
-	vc  int32
-	ue  *UnpackedEnvelope
-	env []byte
+	vc int32
+	// status is only decoded when the committer processed the transaction
+	status    protoblocktx.Status
+	hasStatus bool
+	ue        *UnpackedEnvelope
+	env       []byte
 }
 
 func NewProcessedTransactionFromEnvelopePayload(payload []byte) (*processedTransaction, int32, error) {
@@ -128,7 +151,8 @@ func NewProcessedTransactionFromEnvelopeRaw(env []byte) (*processedTransaction,
 	return &processedTransaction{ue: ue, env: env}, nil
 }
 
-func NewProcessedTransaction(raw []byte) (*processedTransaction, error) {
+// NewProcessedTransaction decodes the validation code of the processed transaction into its fabricx status.
+func NewProcessedTransaction(raw []byte, adapter protoblocktx.Marshaller) (*processedTransaction, error) {
 	pt := &pb.ProcessedTransaction{}
 	if err := proto.Unmarshal(raw, pt); err != nil {
 		return nil, errors.Wrap(err, "unmarshal failed")
@@ -141,7 +165,8 @@ func NewProcessedTransaction(raw []byte) (*processedTransaction, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &processedTransaction{vc: pt.ValidationCode, ue: ue, env: env}, nil
+	status := adapter.DecodeStatus(byte(pt.ValidationCode))
+	return &processedTransaction{vc: status.Code, status: status, hasStatus: true, ue: ue, env: env}, nil
 }
 
 func (p *processedTransaction) TxID() string {
@@ -153,7 +178,10 @@ func (p *processedTransaction) Results() []byte {
 }
 
 func (p *processedTransaction) IsValid() bool {
-	return p.vc == int32(pb.TxValidationCode_VALID)
+	if !p.hasStatus {
+		return p.vc == int32(pb.TxValidationCode_VALID)
+	}
+	return p.status.Valid
 }
 
 func (p *processedTransaction) Envelope() []byte {
@@ -163,3 +191,9 @@ func (p *processedTransaction) Envelope() []byte {
 func (p *processedTransaction) ValidationCode() int32 {
 	return p.vc
 }
+
+// Status returns the fabricx status of the transaction.
+// It is only known for transactions built with NewProcessedTransaction.
+func (p *processedTransaction) Status() protoblocktx.Status {
+	return p.status
+}
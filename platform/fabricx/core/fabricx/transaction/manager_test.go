@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	driver2 "github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+const batchTransaction driver.TransactionType = 100
+
+// typedFactory returns transactions that only record the factory they come from.
+type typedFactory struct{ txType driver.TransactionType }
+
+func (f typedFactory) NewTransaction(context.Context, string, []byte, []byte, driver2.TxID, []byte) (driver.Transaction, error) {
+	return &typedTransaction{txType: f.txType}, nil
+}
+
+type typedTransaction struct {
+	driver.Transaction
+	txType driver.TransactionType
+	raw    []byte
+}
+
+func (t *typedTransaction) SetFromBytes(raw []byte) error {
+	t.raw = raw
+	return nil
+}
+
+func TestNewTransactionFromBytes(t *testing.T) {
+	m := NewManager(nil)
+	m.AddTransactionFactory(driver.EndorserTransaction, typedFactory{txType: driver.EndorserTransaction})
+	m.AddTransactionFactory(batchTransaction, typedFactory{txType: batchTransaction})
+
+	batch := wireTestTransaction()
+	batch.TType = batchTransaction
+	raw, err := marshalTransaction(batch, ProtobufEncoding)
+	require.NoError(t, err)
+	tx, err := m.NewTransactionFromBytes(context.Background(), "channel", raw)
+	require.NoError(t, err)
+	require.Equal(t, batchTransaction, tx.(*typedTransaction).txType)
+	require.Equal(t, raw, tx.(*typedTransaction).raw)
+
+	// transactions serialized before the type was recorded are endorser transactions
+	raw, err = json.Marshal(struct{ TTxID string }{TTxID: "tx1"})
+	require.NoError(t, err)
+	tx, err = m.NewTransactionFromBytes(context.Background(), "channel", raw)
+	require.NoError(t, err)
+	require.Equal(t, driver.EndorserTransaction, tx.(*typedTransaction).txType)
+
+	unknown := wireTestTransaction()
+	unknown.TType = batchTransaction + 1
+	raw, err = marshalTransaction(unknown, ProtobufEncoding)
+	require.NoError(t, err)
+	_, err = m.NewTransactionFromBytes(context.Background(), "channel", raw)
+	require.Error(t, err)
+}
+
+// channelFNS returns channels whose membership is not needed by the transactions under test
+type channelFNS struct{ driver.FabricNetworkService }
+
+func (channelFNS) Channel(string) (driver.Channel, error) { return membershipChannel{}, nil }
+
+type membershipChannel struct{ driver.Channel }
+
+func (membershipChannel) ChannelMembership() driver.ChannelMembership { return nil }
+
+func TestBytesNoTransientKeepsType(t *testing.T) {
+	tx := wireTestTransaction()
+	tx.TType = batchTransaction
+	tx.TSignedProposal = nil
+	tx.TProposalResponses = nil
+	tx.fns = channelFNS{}
+
+	raw, err := tx.BytesNoTransient()
+	require.NoError(t, err)
+	loaded := &Transaction{fns: channelFNS{}}
+	require.NoError(t, loaded.SetFromBytes(raw))
+	require.Equal(t, batchTransaction, loaded.Type())
+	require.Equal(t, tx.TCreator, loaded.TCreator)
+	require.Empty(t, loaded.TTransient)
+}
+
+func TestNewProcessedTransactionStatus(t *testing.T) {
+	chdr := protoutil.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "mychannel", 0)
+	chdr.TxId = "tx1"
+	shdr := &common.SignatureHeader{Creator: []byte("alice"), Nonce: []byte("nonce")}
+	env := &common.Envelope{Payload: protoutil.MarshalOrPanic(&common.Payload{Header: protoutil.MakePayloadHeader(chdr, shdr)})}
+	m := NewManager(v2.NewMarshallerAdapter())
+
+	for _, tc := range []struct {
+		status v2.Status
+		valid  bool
+	}{
+		{status: v2.Status_COMMITTED, valid: true},
+		{status: v2.Status_ABORTED_MVCC_CONFLICT},
+		{status: v2.Status_ABORTED_SIGNATURE_INVALID},
+	} {
+		raw, err := proto.Marshal(&pb.ProcessedTransaction{TransactionEnvelope: env, ValidationCode: int32(tc.status)})
+		require.NoError(t, err)
+		pt, err := m.NewProcessedTransaction(raw)
+		require.NoError(t, err)
+		require.Equal(t, "tx1", pt.TxID())
+		require.Equal(t, int32(tc.status), pt.ValidationCode())
+		require.Equal(t, tc.valid, pt.IsValid(), tc.status.String())
+	}
+
+	// the envelopes not processed by the committer yet carry no status
+	raw, err := proto.Marshal(env)
+	require.NoError(t, err)
+	pt, err := m.NewProcessedTransactionFromEnvelopeRaw(raw)
+	require.NoError(t, err)
+	require.True(t, pt.IsValid())
+	pt, _, err = m.NewProcessedTransactionFromEnvelopePayload(env.Payload)
+	require.NoError(t, err)
+	require.True(t, pt.IsValid())
+}
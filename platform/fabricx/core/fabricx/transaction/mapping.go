@@ -0,0 +1,93 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+)
+
+// NamespaceMapping binds a chaincode, and optionally one of its functions, to a fabricx namespace.
+// An empty Channel matches any channel, an empty Function any function of the chaincode.
+// An entry with an empty Chaincode is the default used for new transactions on the channel.
+type NamespaceMapping struct {
+	Channel   string `yaml:"channel,omitempty"`
+	Chaincode string `yaml:"chaincode,omitempty"`
+	Function  string `yaml:"function,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Version   string `yaml:"version,omitempty"`
+}
+
+// NamespaceMapper turns the chaincode and function of a classic fabric proposal
+// into the fabricx namespace and namespace version the transaction is bound to.
+type NamespaceMapper interface {
+	Map(channel, chaincode, version, function string) (namespace string, nsVersion string)
+}
+
+type ConfigService interface {
+	UnmarshalKey(key string, rawVal interface{}) error
+}
+
+// NewNamespaceMapperFromConfig reads the mappings of the network from the `namespaceMapping` key.
+func NewNamespaceMapperFromConfig(configService ConfigService) (NamespaceMapper, error) {
+	var mappings []NamespaceMapping
+	if err := configService.UnmarshalKey("namespaceMapping", &mappings); err != nil {
+		return nil, errors.Wrap(err, "cannot get namespace mapping config")
+	}
+	return NewStaticNamespaceMapper(mappings...)
+}
+
+// NewStaticNamespaceMapper returns a mapper over the passed mappings.
+// Chaincodes without a mapping keep their name and version, so that existing deployments
+// naming their namespaces after the chaincode work unchanged.
+func NewStaticNamespaceMapper(mappings ...NamespaceMapping) (NamespaceMapper, error) {
+	m := &staticNamespaceMapper{mappings: make(map[mappingKey]NamespaceMapping, len(mappings))}
+	for _, mapping := range mappings {
+		if len(mapping.Chaincode) == 0 && len(mapping.Function) != 0 {
+			return nil, errors.Errorf("namespace mapping for function [%s] has no chaincode", mapping.Function)
+		}
+		if len(mapping.Chaincode) != 0 && len(mapping.Namespace) == 0 {
+			return nil, errors.Errorf("namespace mapping for chaincode [%s] has no namespace", mapping.Chaincode)
+		}
+		key := mappingKey{channel: mapping.Channel, chaincode: mapping.Chaincode, function: mapping.Function}
+		if _, ok := m.mappings[key]; ok {
+			return nil, errors.Errorf("duplicate namespace mapping for [%s:%s:%s]", mapping.Channel, mapping.Chaincode, mapping.Function)
+		}
+		m.mappings[key] = mapping
+	}
+	return m, nil
+}
+
+type mappingKey struct {
+	channel   string
+	chaincode string
+	function  string
+}
+
+type staticNamespaceMapper struct {
+	mappings map[mappingKey]NamespaceMapping
+}
+
+// Map looks up the most specific mapping: channel and function bound mappings win over
+// channel-wide and function-wide ones.
+func (m *staticNamespaceMapper) Map(channel, chaincode, version, function string) (string, string) {
+	candidates := []mappingKey{
+		{channel: channel, chaincode: chaincode, function: function},
+		{channel: channel, chaincode: chaincode},
+		{chaincode: chaincode, function: function},
+		{chaincode: chaincode},
+	}
+	for _, key := range candidates {
+		if len(key.chaincode) == 0 && len(key.function) != 0 {
+			continue
+		}
+		if mapping, ok := m.mappings[key]; ok {
+			logger.Debugf("map [%s:%s:%s] to namespace [%s:%s]", channel, chaincode, function, mapping.Namespace, mapping.Version)
+			return mapping.Namespace, mapping.Version
+		}
+	}
+	return chaincode, version
+}
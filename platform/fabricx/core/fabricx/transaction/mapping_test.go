@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticNamespaceMapper(t *testing.T) {
+	m, err := NewStaticNamespaceMapper(
+		NamespaceMapping{Namespace: "default", Version: "0"},
+		NamespaceMapping{Channel: "ch2", Namespace: "default2", Version: "0"},
+		NamespaceMapping{Chaincode: "asset", Namespace: "assets", Version: "1"},
+		NamespaceMapping{Chaincode: "asset", Function: "audit", Namespace: "audit", Version: "1"},
+		NamespaceMapping{Channel: "ch2", Chaincode: "asset", Namespace: "assets2", Version: "2"},
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		channel, chaincode, version, function string
+		namespace, nsVersion                  string
+	}{
+		{channel: "ch1", namespace: "default", nsVersion: "0"},
+		{channel: "ch2", namespace: "default2", nsVersion: "0"},
+		{channel: "ch1", chaincode: "asset", version: "9", function: "create", namespace: "assets", nsVersion: "1"},
+		{channel: "ch1", chaincode: "asset", function: "audit", namespace: "audit", nsVersion: "1"},
+		{channel: "ch2", chaincode: "asset", function: "audit", namespace: "assets2", nsVersion: "2"},
+		{channel: "ch1", chaincode: "iou", version: "1", function: "create", namespace: "iou", nsVersion: "1"},
+	} {
+		namespace, nsVersion := m.Map(tc.channel, tc.chaincode, tc.version, tc.function)
+		require.Equal(t, tc.namespace, namespace, "%+v", tc)
+		require.Equal(t, tc.nsVersion, nsVersion, "%+v", tc)
+	}
+
+	_, err = NewStaticNamespaceMapper(NamespaceMapping{Chaincode: "asset"})
+	require.Error(t, err)
+	_, err = NewStaticNamespaceMapper(NamespaceMapping{Function: "audit", Namespace: "audit"})
+	require.Error(t, err)
+	_, err = NewStaticNamespaceMapper(
+		NamespaceMapping{Chaincode: "asset", Namespace: "a"},
+		NamespaceMapping{Chaincode: "asset", Namespace: "b"},
+	)
+	require.Error(t, err)
+}
+
+// namedFNS is a network whose channels are not needed by the transactions under test
+type namedFNS struct{ channelFNS }
+
+func (namedFNS) Name() string { return "network" }
+
+func TestFactoryDefaultNamespace(t *testing.T) {
+	mapper, err := NewStaticNamespaceMapper(NamespaceMapping{Channel: "ch2", Namespace: "default2", Version: "0"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		mapper               NamespaceMapper
+		channel              string
+		namespace, nsVersion string
+	}{
+		{channel: "ch1", namespace: "iou", nsVersion: "1"},
+		{mapper: mapper, channel: "ch1", namespace: "iou", nsVersion: "1"},
+		{mapper: mapper, channel: "ch2", namespace: "default2", nsVersion: "0"},
+	} {
+		tx, err := NewTransactionFactory(namedFNS{}, nil, nil, "", tc.mapper).NewTransaction(context.Background(), tc.channel, []byte("nonce"), []byte("alice"), "", nil)
+		require.NoError(t, err)
+		require.Equal(t, tc.namespace, tx.Chaincode(), "%+v", tc)
+		require.Equal(t, tc.nsVersion, tx.ChaincodeVersion(), "%+v", tc)
+	}
+}
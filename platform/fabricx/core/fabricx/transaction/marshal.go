@@ -20,37 +20,29 @@ import (
 func (t *Transaction) createSCEnvelope() (*pcommon.Envelope, error) {
 	logger.Debugf("generate envelope with sc transaction [txID=%s]", t.ID())
 
-	rawTx, err := t.Results()
+	// check that all endorsers agree on the payload and collect their signatures
+	tx, endorsements, err := CollectEndorsements(t.adapter, t.TProposalResponses)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rws from proposal response: %w", err)
+		return nil, fmt.Errorf("failed to collect endorsements for tx [%s]: %w", t.ID(), err)
 	}
 
-	// append signatures to tx
-	tx, err := t.adapter.UnmarshalTx(rawTx)
+	sigs, err := t.aggregator.Aggregate(tx, endorsements, t.channel.ChannelMembership())
 	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize tx [%s]: %w", t.ID(), err)
+		return nil, fmt.Errorf("failed to aggregate endorsements for tx [%s]: %w", t.ID(), err)
 	}
-
-	// for _, response := range t.ProposalResponses() {
-	//	tx.Signatures = append(tx.Signatures, response.EndorserSignature())
-	//	//tx.Signatures = append(tx.Signatures, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
-	// }
-
-	// just take the first one as we only support single signatures, which are meant to be threshold signatures
-	resps, err := t.ProposalResponses()
-	if err != nil {
-		return nil, err
+	tx = protoblocktx.NewTx(tx.GetId(), tx.GetNamespaces(), sigs)
+	if v, ok := t.adapter.(protoblocktx.Validator); ok {
+		if err := v.ValidateTx(tx); err != nil {
+			return nil, fmt.Errorf("invalid tx [%s]: %w", t.ID(), err)
+		}
 	}
 
-	response := resps[len(resps)-1]
-	tx = protoblocktx.NewTx(tx.GetId(), tx.GetNamespaces(), [][]byte{response.EndorserSignature()})
-
 	if logger.IsEnabledFor(zapcore.DebugLevel) {
 		str, _ := json.MarshalIndent(tx, "", "\t")
 		logger.Debugf("fabricx transaction: %s", str)
 	}
 
-	rawTx, err = t.adapter.MarshalTx(tx)
+	rawTx, err := t.adapter.MarshalTx(tx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal tx [%s]", t.ID())
 	}
@@ -47,8 +47,18 @@ func (p *ProposalResponse) Payload() []byte {
 	return p.pr.Payload
 }
 
+// EndorserSignature returns nil, as a fabricx endorsement carries one signature per namespace
+// and none of them signs the whole response.
+//
+// Deprecated: use EndorserSignatures.
 func (p *ProposalResponse) EndorserSignature() []byte {
-	return p.pr.Endorsement.Signature
+	return nil
+}
+
+// EndorserSignatures returns the per-namespace signatures carried by the endorsement.
+// The i-th signature covers the i-th namespace of the transaction in the payload.
+func (p *ProposalResponse) EndorserSignatures() ([][]byte, error) {
+	return UnmarshalEndorserSignatures(p.pr.Endorsement.Signature)
 }
 
 func (p *ProposalResponse) Results() []byte {
@@ -86,6 +96,21 @@ func (p *ProposalResponse) VerifyEndorsement(provider VerifierProvider) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed unmarshalling payload for [%s]", endorser)
 	}
-	msg := HashTxNamespace(tx.GetId(), tx.GetNamespaces()[0])
-	return v.Verify(msg, p.EndorserSignature())
+	sigs, err := p.EndorserSignatures()
+	if err != nil {
+		return errors.Wrapf(err, "failed unmarshalling signatures for [%s]", endorser)
+	}
+	if len(sigs) != len(tx.GetNamespaces()) {
+		return errors.Errorf("expected [%d] signatures from [%s], got [%d]", len(tx.GetNamespaces()), endorser, len(sigs))
+	}
+	for i, ns := range tx.GetNamespaces() {
+		msg, err := HashTxNamespace(tx.GetId(), ns)
+		if err != nil {
+			return err
+		}
+		if err := v.Verify(msg, sigs[i]); err != nil {
+			return errors.Wrapf(err, "invalid signature from [%s] for namespace [%s]", endorser, ns.GetNsId())
+		}
+	}
+	return nil
 }
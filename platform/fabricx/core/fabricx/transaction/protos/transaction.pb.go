@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v5.29.3
+// source: platform/fabricx/core/fabricx/transaction/protos/transaction.proto
+
+package protos
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Represents a fabricx transaction as exchanged between FSC nodes.
+// Fabric protos are embedded in their serialized form.
+type Transaction struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Version           uint32                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`                                                                               // The version of the encoding.
+	Creator           []byte                 `protobuf:"bytes,2,opt,name=creator,proto3" json:"creator,omitempty"`                                                                                // The identity of the transaction creator.
+	Nonce             []byte                 `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`                                                                                    // The nonce used to compute the transaction ID.
+	TxId              string                 `protobuf:"bytes,4,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`                                                                          // The transaction ID.
+	Network           string                 `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`                                                                                // The network of the transaction.
+	Channel           string                 `protobuf:"bytes,6,opt,name=channel,proto3" json:"channel,omitempty"`                                                                                // The channel of the transaction.
+	Chaincode         string                 `protobuf:"bytes,7,opt,name=chaincode,proto3" json:"chaincode,omitempty"`                                                                            // The chaincode (namespace) of the proposal.
+	ChaincodeVersion  string                 `protobuf:"bytes,8,opt,name=chaincode_version,json=chaincodeVersion,proto3" json:"chaincode_version,omitempty"`                                      // The chaincode version of the proposal.
+	Function          string                 `protobuf:"bytes,9,opt,name=function,proto3" json:"function,omitempty"`                                                                              // The function of the proposal.
+	Parameters        [][]byte               `protobuf:"bytes,10,rep,name=parameters,proto3" json:"parameters,omitempty"`                                                                         // The parameters of the proposal.
+	Rwset             []byte                 `protobuf:"bytes,11,opt,name=rwset,proto3" json:"rwset,omitempty"`                                                                                   // The serialized read-write set.
+	Transient         map[string][]byte      `protobuf:"bytes,12,rep,name=transient,proto3" json:"transient,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // The transient data.
+	Proposal          []byte                 `protobuf:"bytes,13,opt,name=proposal,proto3" json:"proposal,omitempty"`                                                                             // The serialized peer.Proposal.
+	SignedProposal    []byte                 `protobuf:"bytes,14,opt,name=signed_proposal,json=signedProposal,proto3" json:"signed_proposal,omitempty"`                                           // The serialized peer.SignedProposal.
+	ProposalResponses [][]byte               `protobuf:"bytes,15,rep,name=proposal_responses,json=proposalResponses,proto3" json:"proposal_responses,omitempty"`                                  // The serialized peer.ProposalResponse, one per endorser.
+	Type              int32                  `protobuf:"varint,16,opt,name=type,proto3" json:"type,omitempty"`                                                                                    // The driver.TransactionType, endorser transactions when unset.
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Transaction) GetCreator() []byte {
+	if x != nil {
+		return x.Creator
+	}
+	return nil
+}
+
+func (x *Transaction) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *Transaction) GetTxId() string {
+	if x != nil {
+		return x.TxId
+	}
+	return ""
+}
+
+func (x *Transaction) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Transaction) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *Transaction) GetChaincode() string {
+	if x != nil {
+		return x.Chaincode
+	}
+	return ""
+}
+
+func (x *Transaction) GetChaincodeVersion() string {
+	if x != nil {
+		return x.ChaincodeVersion
+	}
+	return ""
+}
+
+func (x *Transaction) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *Transaction) GetParameters() [][]byte {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *Transaction) GetRwset() []byte {
+	if x != nil {
+		return x.Rwset
+	}
+	return nil
+}
+
+func (x *Transaction) GetTransient() map[string][]byte {
+	if x != nil {
+		return x.Transient
+	}
+	return nil
+}
+
+func (x *Transaction) GetProposal() []byte {
+	if x != nil {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *Transaction) GetSignedProposal() []byte {
+	if x != nil {
+		return x.SignedProposal
+	}
+	return nil
+}
+
+func (x *Transaction) GetProposalResponses() [][]byte {
+	if x != nil {
+		return x.ProposalResponses
+	}
+	return nil
+}
+
+func (x *Transaction) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+var File_platform_fabricx_core_fabricx_transaction_protos_transaction_proto protoreflect.FileDescriptor
+
+var file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDesc = string([]byte{
+	0x0a, 0x42, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2f, 0x66, 0x61, 0x62, 0x72, 0x69,
+	0x63, 0x78, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x78, 0x2f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x73, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x13, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x78, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xd2, 0x04, 0x0a, 0x0b, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x14, 0x0a,
+	0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x74, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64, 0x65,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x75, 0x6e, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x75, 0x6e, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72,
+	0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74,
+	0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x77, 0x73, 0x65, 0x74, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x72, 0x77, 0x73, 0x65, 0x74, 0x12, 0x4d, 0x0a, 0x09, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x66,
+	0x61, 0x62, 0x72, 0x69, 0x63, 0x78, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x70,
+	0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x73,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x12, 0x2d, 0x0a,
+	0x12, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x1a, 0x3c, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x5b,
+	0x5a, 0x59, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70,
+	0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2f, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x2d,
+	0x78, 0x2d, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x73, 0x65, 0x72, 0x2f, 0x70, 0x6c, 0x61, 0x74, 0x66,
+	0x6f, 0x72, 0x6d, 0x2f, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x78, 0x2f, 0x63, 0x6f, 0x72, 0x65,
+	0x2f, 0x66, 0x61, 0x62, 0x72, 0x69, 0x63, 0x78, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+})
+
+var (
+	file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescOnce sync.Once
+	file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescData []byte
+)
+
+func file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescGZIP() []byte {
+	file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescOnce.Do(func() {
+		file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDesc), len(file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDesc)))
+	})
+	return file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDescData
+}
+
+var file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_goTypes = []any{
+	(*Transaction)(nil), // 0: fabricx_transaction.Transaction
+	nil,                 // 1: fabricx_transaction.Transaction.TransientEntry
+}
+var file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_depIdxs = []int32{
+	1, // 0: fabricx_transaction.Transaction.transient:type_name -> fabricx_transaction.Transaction.TransientEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_init() }
+func file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_init() {
+	if File_platform_fabricx_core_fabricx_transaction_protos_transaction_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDesc), len(file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_goTypes,
+		DependencyIndexes: file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_depIdxs,
+		MessageInfos:      file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_msgTypes,
+	}.Build()
+	File_platform_fabricx_core_fabricx_transaction_protos_transaction_proto = out.File
+	file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_goTypes = nil
+	file_platform_fabricx_core_fabricx_transaction_protos_transaction_proto_depIdxs = nil
+}
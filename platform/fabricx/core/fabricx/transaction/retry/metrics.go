@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics"
+)
+
+const (
+	outcomeLabel = "outcome"
+
+	committedOutcome = "committed"
+	conflictOutcome  = "mvcc_conflict"
+	failedOutcome    = "failed"
+)
+
+type Metrics struct {
+	Attempts  metrics.Counter
+	Exhausted metrics.Counter
+}
+
+func NewMetrics(p metrics.Provider) *Metrics {
+	return &Metrics{
+		Attempts: p.NewCounter(metrics.CounterOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "mvcc_retry",
+			Name:         "attempts",
+			Help:         "The number of attempts to submit a transaction, by outcome",
+			LabelNames:   []string{outcomeLabel},
+			StatsdFormat: "%{#fqname}.%{" + outcomeLabel + "}",
+		}),
+		Exhausted: p.NewCounter(metrics.CounterOpts{
+			Namespace: "fabricx",
+			Subsystem: "mvcc_retry",
+			Name:      "exhausted",
+			Help:      "The number of transactions still in conflict after the last attempt",
+		}),
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/services/logging"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer"
+)
+
+var logger = logging.MustGetLogger("fabricx.transaction.retry")
+
+// Attempt simulates, endorses and submits a new transaction, then waits for its finality.
+// Each call must create a new transaction, so that it gets a new tx id and reads fresh versions.
+type Attempt func(ctx context.Context, attempt int) (driver.TxID, error)
+
+// Policy tells how many times a transaction aborted because of an MVCC conflict is run again.
+// A MaxAttempts of at most one disables the retries.
+type Policy struct {
+	MaxAttempts int           `yaml:"maxAttempts,omitempty"`
+	Backoff     time.Duration `yaml:"backoff,omitempty"`
+}
+
+type ConfigService interface {
+	UnmarshalKey(key string, rawVal interface{}) error
+}
+
+// NewPolicyFromConfig reads the policy of the network from the `transaction.mvccRetry` key.
+func NewPolicyFromConfig(configService ConfigService) (Policy, error) {
+	policy := Policy{MaxAttempts: 1}
+	if err := configService.UnmarshalKey("transaction.mvccRetry", &policy); err != nil {
+		return policy, errors.Wrap(err, "cannot get mvcc retry config")
+	}
+	return policy, nil
+}
+
+// Event reports the outcome of an attempt.
+// Retry is true when another attempt follows because of an MVCC conflict.
+type Event struct {
+	Attempt int
+	TxID    driver.TxID
+	Err     error
+	Retry   bool
+}
+
+// Listener is notified after each attempt.
+type Listener interface {
+	OnAttempt(ctx context.Context, event Event)
+}
+
+// Resubmitter runs attempts until one is not aborted because of an MVCC conflict,
+// or the attempts of the policy are exhausted.
+type Resubmitter struct {
+	policy  Policy
+	metrics *Metrics
+
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+// NewResubmitter returns a resubmitter with the passed policy.
+// The metrics are registered once, with NewMetrics, and shared by the resubmitters.
+func NewResubmitter(policy Policy, metrics *Metrics) *Resubmitter {
+	return &Resubmitter{policy: policy, metrics: metrics}
+}
+
+func (r *Resubmitter) AddListener(listener Listener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Run runs the attempt and returns the tx id of the last one.
+func (r *Resubmitter) Run(ctx context.Context, attempt Attempt) (driver.TxID, error) {
+	maxAttempts := max(r.policy.MaxAttempts, 1)
+	for i := 1; ; i++ {
+		txID, err := attempt(ctx, i)
+		retry := committer.IsMVCCConflict(err) && i < maxAttempts
+		r.metrics.Attempts.With(outcomeLabel, outcome(err)).Add(1)
+		r.notify(ctx, Event{Attempt: i, TxID: txID, Err: err, Retry: retry})
+		if !retry {
+			if committer.IsMVCCConflict(err) && maxAttempts > 1 {
+				r.metrics.Exhausted.Add(1)
+				return txID, errors.Wrapf(err, "transaction still in conflict after [%d] attempts", i)
+			}
+			return txID, err
+		}
+
+		logger.Infof("transaction [%s] aborted because of an mvcc conflict, attempt [%d] of [%d]", txID, i+1, maxAttempts)
+		if r.policy.Backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return txID, errors.Wrapf(ctx.Err(), "stop retrying transaction [%s]", txID)
+			case <-time.After(r.policy.Backoff):
+			}
+		}
+	}
+}
+
+func (r *Resubmitter) notify(ctx context.Context, event Event) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, l := range r.listeners {
+		l.OnAttempt(ctx, event)
+	}
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return committedOutcome
+	case committer.IsMVCCConflict(err):
+		return conflictOutcome
+	default:
+		return failedOutcome
+	}
+}
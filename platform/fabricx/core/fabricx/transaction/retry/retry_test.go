@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics/disabled"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) OnAttempt(_ context.Context, event Event) {
+	r.events = append(r.events, event)
+}
+
+func conflicting(conflicts int) Attempt {
+	return func(_ context.Context, attempt int) (driver.TxID, error) {
+		txID := fmt.Sprintf("tx%d", attempt)
+		if attempt <= conflicts {
+			return txID, &committer.AbortError{TxID: txID, Status: protoblocktx.Status{Code: 1, Name: "ABORTED_MVCC_CONFLICT", MVCCConflict: true}}
+		}
+		return txID, nil
+	}
+}
+
+func TestRunRetriesConflicts(t *testing.T) {
+	r := NewResubmitter(Policy{MaxAttempts: 3}, NewMetrics(&disabled.Provider{}))
+	l := &recorder{}
+	r.AddListener(l)
+
+	txID, err := r.Run(context.Background(), conflicting(2))
+	require.NoError(t, err)
+	require.Equal(t, "tx3", txID)
+	require.Len(t, l.events, 3)
+	require.True(t, l.events[0].Retry)
+	require.True(t, l.events[1].Retry)
+	require.False(t, l.events[2].Retry)
+	require.NoError(t, l.events[2].Err)
+}
+
+func TestRunExhausted(t *testing.T) {
+	r := NewResubmitter(Policy{MaxAttempts: 2}, NewMetrics(&disabled.Provider{}))
+	l := &recorder{}
+	r.AddListener(l)
+
+	txID, err := r.Run(context.Background(), conflicting(5))
+	require.Error(t, err)
+	require.True(t, committer.IsMVCCConflict(err))
+	require.Equal(t, "tx2", txID)
+	require.Len(t, l.events, 2)
+	require.False(t, l.events[1].Retry)
+}
+
+func TestRunDisabled(t *testing.T) {
+	r := NewResubmitter(Policy{}, NewMetrics(&disabled.Provider{}))
+
+	txID, err := r.Run(context.Background(), conflicting(1))
+	require.True(t, committer.IsMVCCConflict(err))
+	require.Equal(t, "tx1", txID)
+}
+
+func TestRunDoesNotRetryOtherErrors(t *testing.T) {
+	r := NewResubmitter(Policy{MaxAttempts: 3}, NewMetrics(&disabled.Provider{}))
+	calls := 0
+
+	_, err := r.Run(context.Background(), func(context.Context, int) (driver.TxID, error) {
+		calls++
+		return "tx", &committer.AbortError{TxID: "tx", Status: protoblocktx.Status{Code: 3, Name: "ABORTED_SIGNATURE_INVALID"}}
+	})
+	require.Error(t, err)
+	require.False(t, committer.IsMVCCConflict(err))
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	_, err = r.Run(context.Background(), func(context.Context, int) (driver.TxID, error) {
+		calls++
+		return "", errors.New("endorsement failed")
+	})
+	require.EqualError(t, err, "endorsement failed")
+	require.Equal(t, 1, calls)
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	r := NewResubmitter(Policy{MaxAttempts: 3, Backoff: time.Hour}, NewMetrics(&disabled.Provider{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	txID, err := r.Run(ctx, conflicting(5))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, "tx1", txID)
+}
+
+type mapConfig map[string]interface{}
+
+func (c mapConfig) UnmarshalKey(key string, rawVal interface{}) error {
+	v, ok := c[key]
+	if !ok {
+		return nil
+	}
+	*rawVal.(*Policy) = v.(Policy)
+	return nil
+}
+
+func TestNewPolicyFromConfig(t *testing.T) {
+	p, err := NewPolicyFromConfig(mapConfig{})
+	require.NoError(t, err)
+	require.Equal(t, Policy{MaxAttempts: 1}, p)
+
+	p, err = NewPolicyFromConfig(mapConfig{"transaction.mvccRetry": Policy{MaxAttempts: 4, Backoff: time.Second}})
+	require.NoError(t, err)
+	require.Equal(t, Policy{MaxAttempts: 4, Backoff: time.Second}, p)
+}
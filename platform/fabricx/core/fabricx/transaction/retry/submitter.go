@@ -0,0 +1,176 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/driver/config"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+)
+
+// Simulation fills the read-write set of a new transaction.
+// It is run again for each attempt, so that the reads get the versions committed in the meantime.
+type Simulation func(ctx context.Context, tx *fabric.Transaction) error
+
+// Endorsement endorses a simulated transaction before it is submitted.
+type Endorsement func(ctx context.Context, tx *fabric.Transaction) error
+
+// Submitter submits the transactions of a channel and waits for their finality,
+// simulating and endorsing them again as long as they are aborted because of an MVCC conflict.
+// The retries are opt-in per caller: the transactions broadcast through the ordering service
+// of the network are never run again, as only the caller can simulate them anew.
+type Submitter struct {
+	*Resubmitter
+
+	fns        *fabric.NetworkService
+	ch         *fabric.Channel
+	marshaller protoblocktx.Marshaller
+}
+
+func NewSubmitter(resubmitter *Resubmitter, fns *fabric.NetworkService, ch *fabric.Channel, marshaller protoblocktx.Marshaller) *Submitter {
+	return &Submitter{Resubmitter: resubmitter, fns: fns, ch: ch, marshaller: marshaller}
+}
+
+// Submit runs the simulation on a new transaction, endorses it with the default identity and submits it.
+// It returns the tx id of the last attempt.
+func (s *Submitter) Submit(ctx context.Context, simulate Simulation) (driver.TxID, error) {
+	return s.SubmitWithEndorsement(ctx, simulate, func(_ context.Context, tx *fabric.Transaction) error {
+		return tx.Endorse()
+	})
+}
+
+// SubmitWithEndorsement is as Submit, with the endorsement collected by the passed function.
+func (s *Submitter) SubmitWithEndorsement(ctx context.Context, simulate Simulation, endorse Endorsement) (driver.TxID, error) {
+	return s.Run(ctx, func(ctx context.Context, attempt int) (driver.TxID, error) {
+		tx, err := s.fns.TransactionManager().NewTransaction(fabric.WithContext(ctx), fabric.WithChannel(s.ch.Name()))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed creating transaction")
+		}
+		defer tx.Close()
+		logger.Debugf("attempt [%d] with transaction [%s]", attempt, tx.ID())
+
+		if err := tx.SetRWSet(); err != nil {
+			return tx.ID(), errors.Wrapf(err, "failed creating rwset of transaction [%s]", tx.ID())
+		}
+		if err := simulate(ctx, tx); err != nil {
+			return tx.ID(), errors.Wrapf(err, "failed simulating transaction [%s]", tx.ID())
+		}
+		if err := endorse(ctx, tx); err != nil {
+			return tx.ID(), errors.Wrapf(err, "failed endorsing transaction [%s]", tx.ID())
+		}
+		return tx.ID(), s.broadcast(ctx, tx)
+	})
+}
+
+func (s *Submitter) broadcast(ctx context.Context, tx *fabric.Transaction) error {
+	// listen before broadcasting, so that the finality event, and its AbortError, are not missed
+	final := make(chan error, 1)
+	go func() { final <- s.ch.Finality().IsFinal(ctx, tx.ID()) }()
+
+	if err := s.fns.Ordering().Broadcast(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed broadcasting transaction [%s]", tx.ID())
+	}
+	if err := <-final; err != nil {
+		return s.finalityError(tx.ID(), err)
+	}
+	return nil
+}
+
+// finalityError returns the AbortError of the transaction, if the committer aborted it.
+// IsFinal only returns it when it waited for the transaction,
+// otherwise the status the committer assigned to the transaction is read from the ledger.
+func (s *Submitter) finalityError(txID driver.TxID, err error) error {
+	if committer.IsMVCCConflict(err) {
+		return err
+	}
+	pt, ledgerErr := s.ch.Ledger().GetTransactionByID(txID)
+	if ledgerErr != nil {
+		return err
+	}
+	if status := s.marshaller.DecodeStatus(byte(pt.ValidationCode())); !status.Valid {
+		return &committer.AbortError{TxID: txID, Status: status}
+	}
+	return err
+}
+
+// Provider returns the submitter of a channel, configured with the retry policy of its network.
+type Provider interface {
+	Get(network, channel string) (*Submitter, error)
+}
+
+func NewProvider(fnsp *fabric.NetworkServiceProvider, configProvider config.Provider, metricsProvider metrics.Provider, adapterProvider protoblocktx.Provider) Provider {
+	return &submitterProvider{
+		fnsp:            fnsp,
+		configProvider:  configProvider,
+		metrics:         NewMetrics(metricsProvider),
+		adapterProvider: adapterProvider,
+		submitters:      map[netCh]*Submitter{},
+	}
+}
+
+type netCh struct{ network, channel string }
+
+type submitterProvider struct {
+	fnsp            *fabric.NetworkServiceProvider
+	configProvider  config.Provider
+	metrics         *Metrics
+	adapterProvider protoblocktx.Provider
+
+	mu         sync.Mutex
+	submitters map[netCh]*Submitter
+}
+
+func (p *submitterProvider) Get(network, channel string) (*Submitter, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.submitters[netCh{network, channel}]; ok {
+		return s, nil
+	}
+
+	fns, err := p.fnsp.FabricNetworkService(network)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fns for [%s] not found", network)
+	}
+	ch, err := fns.Channel(channel)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get channel [%s]", channel)
+	}
+	configService, err := p.configProvider.GetConfig(fns.Name())
+	if err != nil {
+		return nil, err
+	}
+	policy, err := NewPolicyFromConfig(configService)
+	if err != nil {
+		return nil, err
+	}
+	marshaller, err := p.adapterProvider.Get(fns.Name(), ch.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewSubmitter(NewResubmitter(policy, p.metrics), fns, ch, marshaller)
+	p.submitters[netCh{network, channel}] = s
+	return s, nil
+}
+
+// GetSubmitter returns the submitter of the channel.
+func GetSubmitter(sp services.Provider, network, channel string) (*Submitter, error) {
+	p, err := sp.GetService(reflect.TypeOf((*Provider)(nil)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find provider")
+	}
+	return p.(Provider).Get(network, channel)
+}
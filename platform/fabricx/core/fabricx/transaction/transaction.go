@@ -29,17 +29,23 @@ import (
 var logger = logging.MustGetLogger("fabricx.transaction")
 
 type Transaction struct {
-	ctx     context.Context
-	fns     driver.FabricNetworkService
-	adapter protoblocktx.Marshaller
-	rwset   driver.RWSet
+	ctx        context.Context
+	fns        driver.FabricNetworkService
+	adapter    protoblocktx.Marshaller
+	aggregator EndorsementAggregator
+	encoding   string
+	mapper     NamespaceMapper
+	rwset      driver.RWSet
 
 	// TODO: remove channel and use fns(Channel)
 	channel driver.Channel
 
 	signedProposal   *SignedProposal
 	proposalResponse *pb.ProposalResponse
+	// envelope is set when the transaction has been loaded from an envelope
+	envelope *pcommon.Envelope
 
+	TType    driver.TransactionType
 	TCreator view.Identity
 	TNonce   []byte
 	TTxID    string
@@ -59,6 +65,11 @@ type Transaction struct {
 	TProposalResponses []*pb.ProposalResponse
 }
 
+// Type returns the type of the transaction, used to pick the factory when deserializing it.
+func (t *Transaction) Type() driver.TransactionType {
+	return t.TType
+}
+
 func (t *Transaction) Creator() view.Identity {
 	return t.TCreator
 }
@@ -105,6 +116,12 @@ func (t *Transaction) ChaincodeVersion() string {
 }
 
 func (t *Transaction) Results() ([]byte, error) {
+	if len(t.TProposalResponses) == 0 {
+		if len(t.RWSet) == 0 {
+			return nil, fmt.Errorf("no results for transaction [%s]", t.ID())
+		}
+		return t.RWSet, nil
+	}
 	return t.TProposalResponses[0].Payload, nil
 }
 
@@ -115,10 +132,10 @@ func (t *Transaction) From(tx driver.Transaction) (err error) {
 	}
 
 	t.ctx = context.Background()
+	t.TType = payload.TType
 	t.TCreator = payload.TCreator
 	t.TNonce = payload.TNonce
 	t.TTxID = payload.TTxID
-	t.TCreator = payload.TCreator
 	t.TNetwork = payload.TNetwork
 	t.TChannel = payload.TChannel
 	t.TChaincode = payload.TChaincode
@@ -140,26 +157,27 @@ func (t *Transaction) From(tx driver.Transaction) (err error) {
 }
 
 func (t *Transaction) SetFromBytes(raw []byte) error {
-	if err := json.Unmarshal(raw, t); err != nil {
-		return fmt.Errorf("SetFromBytes: failed unmarshalling payload [%s]: %w", string(raw), err)
+	if err := unmarshalTransaction(raw, t); err != nil {
+		return fmt.Errorf("SetFromBytes: failed unmarshalling payload [%x]: %w", raw, err)
+	}
+
+	// Set the channel
+	ch, err := t.fns.Channel(t.Channel())
+	if err != nil {
+		return err
 	}
+	t.channel = ch
 
 	if t.TSignedProposal != nil {
-		// TODO: check the current payload is compatible with the content of the signed proposal
 		up, err := transaction.UnpackSignedProposal(t.TSignedProposal)
 		if err != nil {
 			return fmt.Errorf("SetFromBytes: failed unpacking proposal [%s]: %w", string(raw), err)
 		}
-		t.TTxID = up.TxID()
-		t.TNonce = up.Nonce()
+		if err := verifySignedProposal(t, up, ch.ChannelMembership()); err != nil {
+			return fmt.Errorf("SetFromBytes: transaction [%s] does not match its signed proposal: %w", t.TTxID, err)
+		}
 		t.TChaincode = up.ChaincodeName
 		t.TChaincodeVersion = up.ChaincodeVersion
-		t.TFunction = string(up.Input.Args[0])
-		t.TParameters = [][]byte{}
-		for i := 1; i < len(up.Input.Args); i++ {
-			t.TParameters = append(t.TParameters, up.Input.Args[i])
-		}
-		t.TChannel = up.ChannelHeader.ChannelId
 		t.TProposal = up.Proposal
 		if len(t.TCreator) == 0 {
 			t.TCreator = up.SignatureHeader.Creator
@@ -170,44 +188,54 @@ func (t *Transaction) SetFromBytes(raw []byte) error {
 		}
 	}
 
-	// Set the channel
-	ch, err := t.fns.Channel(t.Channel())
-	if err != nil {
-		return err
+	if err := verifyRWSet(t.adapter, t.TTxID, t.RWSet, t.TProposalResponses, ch.ChannelMembership()); err != nil {
+		return fmt.Errorf("SetFromBytes: transaction [%s] does not match its endorsements: %w", t.TTxID, err)
 	}
-	t.channel = ch
 
 	return nil
 }
 
 func (t *Transaction) SetFromEnvelopeBytes(raw []byte) error {
-	// TODO: check the current payload is compatible with the content of the signed proposal
-	upe, _, err := transaction.UnpackEnvelopeFromBytes(raw)
+	env := &pcommon.Envelope{}
+	if err := proto.Unmarshal(raw, env); err != nil {
+		return fmt.Errorf("SetFromEnvelopeBytes: failed unmarshalling envelope: %w", err)
+	}
+	upe, _, err := UnpackEnvelope(env)
 	if err != nil {
-		return err
+		return fmt.Errorf("SetFromEnvelopeBytes: failed unpacking envelope: %w", err)
 	}
 
-	t.TTxID = upe.TxID
-	t.TNonce = upe.Nonce
-	t.TChannel = upe.Ch
-	t.TChaincode = upe.ChaincodeName
-	t.TChaincodeVersion = upe.ChaincodeVersion
-	t.TFunction = string(upe.Input.Args[0])
-	t.TParameters = [][]byte{}
-	for i := 1; i < len(upe.Input.Args); i++ {
-		t.TParameters = append(t.TParameters, upe.Input.Args[i])
+	tx, err := t.adapter.UnmarshalTx(upe.Results)
+	if err != nil {
+		return fmt.Errorf("SetFromEnvelopeBytes: failed unmarshalling tx [%s]: %w", upe.TxID, err)
 	}
-	t.TChannel = upe.ChannelHeader.ChannelId
-	if len(t.TCreator) == 0 {
-		t.TCreator = upe.SignatureHeader.Creator
+	if tx.GetId() != upe.TxID {
+		return fmt.Errorf("SetFromEnvelopeBytes: %w, header [%s], payload [%s]", ErrTxIDMismatch, upe.TxID, tx.GetId())
+	}
+	if len(tx.GetSignatures()) != len(tx.GetNamespaces()) {
+		return fmt.Errorf("SetFromEnvelopeBytes: %w, expected [%d] signatures, got [%d]", ErrRWSetMismatch, len(tx.GetNamespaces()), len(tx.GetSignatures()))
 	}
-	t.TProposalResponses = upe.ProposalResponses
 
-	// Set the channel
-	ch, err := t.fns.Channel(t.Channel())
+	ch, err := t.fns.Channel(upe.Channel)
 	if err != nil {
 		return err
 	}
+	if err := verifyEnvelope(upe, env.Payload, env.Signature, ch.ChannelMembership()); err != nil {
+		return fmt.Errorf("SetFromEnvelopeBytes: envelope [%s] does not match its creator: %w", upe.TxID, err)
+	}
+
+	// the rwset is the transaction as endorsed, that is, without the signatures
+	rwset, err := t.adapter.MarshalTx(protoblocktx.NewTx(tx.GetId(), tx.GetNamespaces(), nil))
+	if err != nil {
+		return fmt.Errorf("SetFromEnvelopeBytes: failed marshalling rwset [%s]: %w", upe.TxID, err)
+	}
+
+	t.TTxID = upe.TxID
+	t.TNonce = upe.Nonce
+	t.TChannel = upe.Channel
+	t.TCreator = upe.Creator
+	t.RWSet = rwset
+	t.envelope = env
 	t.channel = ch
 
 	return nil
@@ -226,7 +254,12 @@ func (t *Transaction) SignedProposal() driver.SignedProposal {
 	return t.signedProposal
 }
 
+// SetProposal sets the proposal of the transaction. The chaincode is translated into
+// the fabricx namespace it is mapped to on the channel.
 func (t *Transaction) SetProposal(chaincode, version, function string, params ...string) {
+	if t.mapper != nil {
+		chaincode, version = t.mapper.Map(t.TChannel, chaincode, version, function)
+	}
 	t.TChaincode = chaincode
 	t.TChaincodeVersion = version
 	t.TFunction = function
@@ -329,7 +362,7 @@ func (t *Transaction) Raw() ([]byte, error) {
 			return nil, err
 		}
 	}
-	return json.Marshal(t)
+	return marshalTransaction(t, t.encoding)
 }
 
 func (t *Transaction) GetRWSet() (driver.RWSet, error) {
@@ -346,7 +379,7 @@ func (t *Transaction) Bytes() ([]byte, error) {
 	if err := t.Done(); err != nil {
 		return nil, err
 	}
-	return json.Marshal(t)
+	return marshalTransaction(t, t.encoding)
 }
 
 func (t *Transaction) BytesNoTransient() ([]byte, error) {
@@ -358,7 +391,7 @@ func (t *Transaction) BytesNoTransient() ([]byte, error) {
 		return nil, err
 	}
 	temp.ResetTransient()
-	return json.Marshal(temp)
+	return marshalTransaction(temp, t.encoding)
 }
 
 func (t *Transaction) Endorse() error {
@@ -499,6 +532,11 @@ func (t *Transaction) ProposalResponse() ([]byte, error) {
 }
 
 func (t *Transaction) Envelope() (driver.Envelope, error) {
+	if t.envelope != nil && len(t.TProposalResponses) == 0 {
+		// the transaction has been loaded from an envelope, return it as is
+		return NewEnvelope(t.TTxID, t.Nonce(), t.TCreator.Bytes(), t.RWSet, t.envelope), nil
+	}
+
 	env, err := t.createSCEnvelope()
 	if err != nil {
 		return nil, fmt.Errorf("could not assemble transaction: %w", err)
@@ -594,17 +632,20 @@ func (t *Transaction) getProposalResponse(signer SerializableSigner) (*pb.Propos
 		return nil, fmt.Errorf("could not get the signer's identity: %w", err)
 	}
 
-	// NOTE: we should go through all namespaces and sign them ... currently a single namespace works
 	if logger.IsEnabledFor(zapcore.DebugLevel) {
 		jsonTx, _ := json.Marshal(tx)
 		logger.Debugf("endorse tx [%s]", string(jsonTx))
 	}
 
-	h := HashTxNamespace(tx.GetId(), tx.GetNamespaces()[0])
-	signature, err := signer.Sign(h)
+	// sign each namespace, the committer verifies one signature per namespace
+	sigs, err := SignTxNamespaces(signer, tx)
 	if err != nil {
 		return nil, fmt.Errorf("could not sign the proposal response payload: %w", err)
 	}
+	signature, err := MarshalEndorserSignatures(sigs)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the proposal response signatures: %w", err)
+	}
 
 	return &pb.ProposalResponse{
 		Version:     1,
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/transaction"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+)
+
+// Errors returned when a loaded transaction does not agree with what its creator and endorsers signed.
+var (
+	ErrInvalidCreatorSignature = errors.New("invalid creator signature")
+	ErrCreatorMismatch         = errors.New("creator mismatch")
+	ErrTxIDMismatch            = errors.New("tx id mismatch")
+	ErrNonceMismatch           = errors.New("nonce mismatch")
+	ErrChannelMismatch         = errors.New("channel mismatch")
+	ErrRWSetMismatch           = errors.New("rwset mismatch")
+)
+
+// verifySignedProposal checks that the proposal is signed by its creator and
+// that the transaction carries the creator, tx id, nonce and channel of the proposal header.
+// The function and parameters are not checked: callers may change them after the proposal is signed,
+// and the committer only processes the endorsed rwset, that verifyRWSet checks.
+func verifySignedProposal(t *Transaction, up *transaction.UnpackedProposal, verifierProvider VerifierProvider) error {
+	creator := view.Identity(up.SignatureHeader.Creator)
+	if len(t.TCreator) != 0 && !t.TCreator.Equal(creator) {
+		return errors.Wrapf(ErrCreatorMismatch, "expected [%s], proposal [%s]", t.TCreator, creator)
+	}
+	if err := verifyCreatorSignature(creator, up.SignedProposal.ProposalBytes, up.SignedProposal.Signature, verifierProvider); err != nil {
+		return err
+	}
+
+	if txID := protoutil.ComputeTxID(up.Nonce(), creator); txID != up.TxID() {
+		return errors.Wrapf(ErrTxIDMismatch, "proposal [%s], computed [%s]", up.TxID(), txID)
+	}
+	if t.TTxID != up.TxID() {
+		return errors.Wrapf(ErrTxIDMismatch, "expected [%s], proposal [%s]", t.TTxID, up.TxID())
+	}
+	if !bytes.Equal(t.TNonce, up.Nonce()) {
+		return errors.Wrapf(ErrNonceMismatch, "expected [%x], proposal [%x]", t.TNonce, up.Nonce())
+	}
+	if t.TChannel != up.ChannelID() {
+		return errors.Wrapf(ErrChannelMismatch, "expected [%s], proposal [%s]", t.TChannel, up.ChannelID())
+	}
+	return nil
+}
+
+// verifyEnvelope checks that the envelope is signed by its creator and that the tx id derives from it.
+func verifyEnvelope(upe *UnpackedEnvelope, payload, signature []byte, verifierProvider VerifierProvider) error {
+	if err := verifyCreatorSignature(upe.Creator, payload, signature, verifierProvider); err != nil {
+		return err
+	}
+	if txID := protoutil.ComputeTxID(upe.Nonce, upe.Creator); txID != upe.TxID {
+		return errors.Wrapf(ErrTxIDMismatch, "envelope [%s], computed [%s]", upe.TxID, txID)
+	}
+	return nil
+}
+
+func verifyCreatorSignature(creator view.Identity, message, signature []byte, verifierProvider VerifierProvider) error {
+	v, err := verifierProvider.GetVerifier(creator)
+	if err != nil {
+		return fmt.Errorf("failed getting verifier for [%s]: %w: %w", creator, err, ErrInvalidCreatorSignature)
+	}
+	if err := v.Verify(message, signature); err != nil {
+		return fmt.Errorf("signature of [%s] does not verify: %w: %w", creator, err, ErrInvalidCreatorSignature)
+	}
+	return nil
+}
+
+// verifyRWSet checks that each endorsement signs the namespaces of the passed rwset.
+func verifyRWSet(adapter protoblocktx.Marshaller, txID string, rwset []byte, responses []*pb.ProposalResponse, verifierProvider VerifierProvider) error {
+	if len(rwset) == 0 || len(responses) == 0 {
+		return nil
+	}
+	tx, err := adapter.UnmarshalTx(rwset)
+	if err != nil {
+		return fmt.Errorf("failed unmarshalling rwset of [%s]: %w: %w", txID, err, ErrRWSetMismatch)
+	}
+	if tx.GetId() != txID {
+		return errors.Wrapf(ErrTxIDMismatch, "expected [%s], rwset [%s]", txID, tx.GetId())
+	}
+	for i, resp := range responses {
+		if resp.GetEndorsement() == nil {
+			return errors.Wrapf(ErrRWSetMismatch, "proposal response [%d] has no endorsement", i)
+		}
+		endorser := view.Identity(resp.Endorsement.Endorser)
+		sigs, err := UnmarshalEndorserSignatures(resp.Endorsement.Signature)
+		if err != nil {
+			return fmt.Errorf("invalid endorsement from [%s]: %w: %w", endorser, err, ErrRWSetMismatch)
+		}
+		if len(sigs) != len(tx.GetNamespaces()) {
+			return errors.Wrapf(ErrRWSetMismatch, "expected [%d] signatures from [%s], got [%d]", len(tx.GetNamespaces()), endorser, len(sigs))
+		}
+		if err := verifyEndorsement(tx, Endorsement{Endorser: endorser, Signatures: sigs}, verifierProvider); err != nil {
+			return fmt.Errorf("%w: %w", err, ErrRWSetMismatch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/transaction"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	pcommon "github.com/hyperledger/fabric-protos-go/common"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+)
+
+var errNoVerifier = errors.New("no verifier")
+
+// failingVerifierProvider has no verifier for any identity
+type failingVerifierProvider struct{}
+
+func (failingVerifierProvider) GetVerifier(view.Identity) (driver.Verifier, error) {
+	return nil, errNoVerifier
+}
+
+func signedProposal(t *testing.T, creator []byte, nonce []byte, channel string, args ...string) *pb.SignedProposal {
+	t.Helper()
+	input := &pb.ChaincodeInput{}
+	for _, arg := range args {
+		input.Args = append(input.Args, []byte(arg))
+	}
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: "iou"}, Input: input}}
+	txID := protoutil.ComputeTxID(nonce, creator)
+	prop, _, err := protoutil.CreateChaincodeProposalWithTxIDNonceAndTransient(txID, pcommon.HeaderType_ENDORSER_TRANSACTION, channel, cis, nonce, creator, nil)
+	require.NoError(t, err)
+	raw, err := proto.Marshal(prop)
+	require.NoError(t, err)
+	sig, err := prefixSigner{id: creator}.Sign(raw)
+	require.NoError(t, err)
+	return &pb.SignedProposal{ProposalBytes: raw, Signature: sig}
+}
+
+func TestVerifySignedProposal(t *testing.T) {
+	creator, nonce := []byte("alice"), []byte("nonce")
+	sp := signedProposal(t, creator, nonce, "channel", "create", "p1", "p2")
+	up, err := transaction.UnpackSignedProposal(sp)
+	require.NoError(t, err)
+
+	matching := func() *Transaction {
+		return &Transaction{
+			TCreator:    creator,
+			TNonce:      nonce,
+			TTxID:       protoutil.ComputeTxID(nonce, creator),
+			TChannel:    "channel",
+			TFunction:   "create",
+			TParameters: [][]byte{[]byte("p1"), []byte("p2")},
+		}
+	}
+	require.NoError(t, verifySignedProposal(matching(), up, prefixVerifierProvider{}))
+
+	for name, tc := range map[string]struct {
+		tamper   func(tx *Transaction)
+		expected error
+	}{
+		"creator": {func(tx *Transaction) { tx.TCreator = []byte("bob") }, ErrCreatorMismatch},
+		"tx id":   {func(tx *Transaction) { tx.TTxID = "tx2" }, ErrTxIDMismatch},
+		"nonce":   {func(tx *Transaction) { tx.TNonce = []byte("other") }, ErrNonceMismatch},
+		"channel": {func(tx *Transaction) { tx.TChannel = "other" }, ErrChannelMismatch},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tx := matching()
+			tc.tamper(tx)
+			require.ErrorIs(t, verifySignedProposal(tx, up, prefixVerifierProvider{}), tc.expected)
+		})
+	}
+
+	t.Run("parameters changed after signing", func(t *testing.T) {
+		tx := matching()
+		tx.TFunction = "delete"
+		tx.TParameters = append(tx.TParameters, []byte("p3"))
+		require.NoError(t, verifySignedProposal(tx, up, prefixVerifierProvider{}))
+
+		// the transaction still loads once serialized
+		tx.fns = envelopeFNS{}
+		tx.TSignedProposal = sp
+		raw, err := tx.Bytes()
+		require.NoError(t, err)
+		loaded := &Transaction{fns: envelopeFNS{}}
+		require.NoError(t, loaded.SetFromBytes(raw))
+		require.Equal(t, tx.TParameters, loaded.TParameters)
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		forged := signedProposal(t, creator, nonce, "channel", "create", "p1", "p2")
+		forged.Signature = []byte("forged")
+		up, err := transaction.UnpackSignedProposal(forged)
+		require.NoError(t, err)
+		require.ErrorIs(t, verifySignedProposal(matching(), up, prefixVerifierProvider{}), ErrInvalidCreatorSignature)
+	})
+
+	t.Run("cause", func(t *testing.T) {
+		up, err := transaction.UnpackSignedProposal(signedProposal(t, creator, nonce, "channel", "create", "p1", "p2"))
+		require.NoError(t, err)
+		err = verifySignedProposal(matching(), up, failingVerifierProvider{})
+		require.ErrorIs(t, err, ErrInvalidCreatorSignature)
+		require.ErrorIs(t, err, errNoVerifier)
+	})
+}
+
+func TestVerifyRWSet(t *testing.T) {
+	adapter := v2.NewMarshallerAdapter()
+	tx := testTx()
+	rwset, err := adapter.MarshalTx(tx)
+	require.NoError(t, err)
+	responses := []*pb.ProposalResponse{endorse(t, "alice", rwset, tx), endorse(t, "bob", rwset, tx)}
+
+	require.NoError(t, verifyRWSet(adapter, tx.GetId(), rwset, responses, prefixVerifierProvider{}))
+	require.NoError(t, verifyRWSet(adapter, tx.GetId(), nil, responses, prefixVerifierProvider{}))
+	require.ErrorIs(t, verifyRWSet(adapter, "tx2", rwset, responses, prefixVerifierProvider{}), ErrTxIDMismatch)
+
+	// the rwset carried by the transaction is not the one the endorsers signed
+	other, err := adapter.MarshalTx(protoblocktx.NewTx(tx.GetId(), []protoblocktx.TxNamespace{
+		protoblocktx.NewTxNamespace("ns1", nil, nil, nil, []protoblocktx.Write{protoblocktx.NewWrite([]byte("k1"), []byte("v3"))}),
+		tx.GetNamespaces()[1],
+	}, nil))
+	require.NoError(t, err)
+	require.ErrorIs(t, verifyRWSet(adapter, tx.GetId(), other, responses, prefixVerifierProvider{}), ErrRWSetMismatch)
+
+	// the cause is kept along with the mismatch
+	err = verifyRWSet(adapter, tx.GetId(), rwset, responses, failingVerifierProvider{})
+	require.ErrorIs(t, err, ErrRWSetMismatch)
+	require.ErrorIs(t, err, errNoVerifier)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/transaction/protos"
+	protov2 "google.golang.org/protobuf/proto"
+)
+
+const (
+	// ProtobufEncoding serializes transactions with the versioned protobuf wire format.
+	// Older releases cannot decode it, hence it must only be configured once all the nodes have upgraded.
+	ProtobufEncoding = "protobuf"
+	// JSONEncoding serializes transactions as JSON, as done by older releases. It is the default.
+	JSONEncoding = "json"
+
+	// WireVersion is the version of the protobuf wire format produced by this release.
+	WireVersion uint32 = 1
+)
+
+// marshalTransaction serializes the transaction with the passed encoding, JSON if none.
+func marshalTransaction(t *Transaction, encoding string) ([]byte, error) {
+	switch encoding {
+	case ProtobufEncoding:
+		return marshalProtoTransaction(t)
+	case "", JSONEncoding:
+		return json.Marshal(t)
+	default:
+		return nil, errors.Errorf("unknown transaction encoding [%s]", encoding)
+	}
+}
+
+// unmarshalTransaction decodes both the protobuf wire format and the legacy JSON one into the transaction.
+func unmarshalTransaction(raw []byte, t *Transaction) error {
+	if isJSON(raw) {
+		if err := json.Unmarshal(raw, t); err != nil {
+			return err
+		}
+		t.TType = transactionType(int32(t.TType))
+		return nil
+	}
+	return unmarshalProtoTransaction(raw, t)
+}
+
+// TransactionTypeFromBytes returns the type of the serialized transaction.
+// Transactions serialized before the type was recorded are endorser transactions.
+func TransactionTypeFromBytes(raw []byte) (driver.TransactionType, error) {
+	if isJSON(raw) {
+		t := &struct{ TType driver.TransactionType }{}
+		if err := json.Unmarshal(raw, t); err != nil {
+			return 0, errors.Wrap(err, "failed unmarshalling transaction")
+		}
+		return transactionType(int32(t.TType)), nil
+	}
+	t := &Transaction{}
+	if err := unmarshalProtoTransaction(raw, t); err != nil {
+		return 0, err
+	}
+	return t.TType, nil
+}
+
+func transactionType(t int32) driver.TransactionType {
+	if t == 0 {
+		return driver.EndorserTransaction
+	}
+	return driver.TransactionType(t)
+}
+
+func isJSON(raw []byte) bool {
+	// a JSON object starts with '{', which is not a valid first byte of our protobuf encoding
+	// because the version (field 1) always comes first.
+	trimmed := bytes.TrimLeft(raw, " \t\r\n")
+	return len(trimmed) != 0 && trimmed[0] == '{'
+}
+
+func marshalProtoTransaction(t *Transaction) ([]byte, error) {
+	msg := &protos.Transaction{
+		Version:          WireVersion,
+		Type:             int32(t.TType),
+		Creator:          t.TCreator,
+		Nonce:            t.TNonce,
+		TxId:             t.TTxID,
+		Network:          t.TNetwork,
+		Channel:          t.TChannel,
+		Chaincode:        t.TChaincode,
+		ChaincodeVersion: t.TChaincodeVersion,
+		Function:         t.TFunction,
+		Parameters:       t.TParameters,
+		Rwset:            t.RWSet,
+		Transient:        t.TTransient,
+	}
+
+	var err error
+	if t.TProposal != nil {
+		if msg.Proposal, err = proto.Marshal(t.TProposal); err != nil {
+			return nil, errors.Wrap(err, "failed marshalling proposal")
+		}
+	}
+	if t.TSignedProposal != nil {
+		if msg.SignedProposal, err = proto.Marshal(t.TSignedProposal); err != nil {
+			return nil, errors.Wrap(err, "failed marshalling signed proposal")
+		}
+	}
+	for i, resp := range t.TProposalResponses {
+		raw, err := proto.Marshal(resp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed marshalling proposal response [%d]", i)
+		}
+		msg.ProposalResponses = append(msg.ProposalResponses, raw)
+	}
+
+	// deterministic, so that the transient map does not change the bytes from run to run
+	return protov2.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+func unmarshalProtoTransaction(raw []byte, t *Transaction) error {
+	msg := &protos.Transaction{}
+	if err := proto.Unmarshal(raw, msg); err != nil {
+		return errors.Wrap(err, "failed unmarshalling transaction")
+	}
+	if msg.GetVersion() == 0 || msg.GetVersion() > WireVersion {
+		return errors.Errorf("unsupported transaction wire version [%d], supported up to [%d]", msg.GetVersion(), WireVersion)
+	}
+
+	t.TType = transactionType(msg.GetType())
+	t.TCreator = msg.GetCreator()
+	t.TNonce = msg.GetNonce()
+	t.TTxID = msg.GetTxId()
+	t.TNetwork = msg.GetNetwork()
+	t.TChannel = msg.GetChannel()
+	t.TChaincode = msg.GetChaincode()
+	t.TChaincodeVersion = msg.GetChaincodeVersion()
+	t.TFunction = msg.GetFunction()
+	t.TParameters = msg.GetParameters()
+	t.RWSet = msg.GetRwset()
+	t.TTransient = driver.TransientMap(msg.GetTransient())
+	if t.TTransient == nil {
+		t.TTransient = driver.TransientMap{}
+	}
+
+	t.TProposal = nil
+	if len(msg.GetProposal()) != 0 {
+		t.TProposal = &pb.Proposal{}
+		if err := proto.Unmarshal(msg.GetProposal(), t.TProposal); err != nil {
+			return errors.Wrap(err, "failed unmarshalling proposal")
+		}
+	}
+	t.TSignedProposal = nil
+	if len(msg.GetSignedProposal()) != 0 {
+		t.TSignedProposal = &pb.SignedProposal{}
+		if err := proto.Unmarshal(msg.GetSignedProposal(), t.TSignedProposal); err != nil {
+			return errors.Wrap(err, "failed unmarshalling signed proposal")
+		}
+	}
+	t.TProposalResponses = nil
+	for i, raw := range msg.GetProposalResponses() {
+		resp := &pb.ProposalResponse{}
+		if err := proto.Unmarshal(raw, resp); err != nil {
+			return errors.Wrapf(err, "failed unmarshalling proposal response [%d]", i)
+		}
+		t.TProposalResponses = append(t.TProposalResponses, resp)
+	}
+	return nil
+}
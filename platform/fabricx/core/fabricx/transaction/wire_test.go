@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package transaction
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/proto"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/view"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/transaction/protos"
+	"github.com/stretchr/testify/require"
+)
+
+func wireTestTransaction() *Transaction {
+	return &Transaction{
+		TType:              driver.EndorserTransaction,
+		TCreator:           []byte("alice"),
+		TNonce:             []byte("nonce"),
+		TTxID:              "tx1",
+		TNetwork:           "network",
+		TChannel:           "channel",
+		TChaincode:         "iou",
+		TChaincodeVersion:  "1",
+		TFunction:          "create",
+		TParameters:        [][]byte{[]byte("p1"), []byte("p2")},
+		RWSet:              []byte("rwset"),
+		TTransient:         driver.TransientMap{"k1": []byte("v1"), "k2": []byte("v2")},
+		TProposal:          &pb.Proposal{Header: []byte("header"), Payload: []byte("payload")},
+		TSignedProposal:    &pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("signature")},
+		TProposalResponses: []*pb.ProposalResponse{{Version: 1, Payload: []byte("payload"), Endorsement: &pb.Endorsement{Endorser: []byte("bob")}}},
+	}
+}
+
+func requireSameTransaction(t *testing.T, expected, actual *Transaction) {
+	t.Helper()
+	require.Equal(t, expected.TType, actual.TType)
+	require.Equal(t, expected.TCreator, actual.TCreator)
+	require.Equal(t, expected.TNonce, actual.TNonce)
+	require.Equal(t, expected.TTxID, actual.TTxID)
+	require.Equal(t, expected.TNetwork, actual.TNetwork)
+	require.Equal(t, expected.TChannel, actual.TChannel)
+	require.Equal(t, expected.TChaincode, actual.TChaincode)
+	require.Equal(t, expected.TChaincodeVersion, actual.TChaincodeVersion)
+	require.Equal(t, expected.TFunction, actual.TFunction)
+	require.Equal(t, expected.TParameters, actual.TParameters)
+	require.Equal(t, expected.RWSet, actual.RWSet)
+	require.Equal(t, expected.TTransient, actual.TTransient)
+	require.True(t, proto.Equal(expected.TProposal, actual.TProposal))
+	require.True(t, proto.Equal(expected.TSignedProposal, actual.TSignedProposal))
+	require.Len(t, actual.TProposalResponses, len(expected.TProposalResponses))
+	for i := range expected.TProposalResponses {
+		require.True(t, proto.Equal(expected.TProposalResponses[i], actual.TProposalResponses[i]))
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	expected := wireTestTransaction()
+
+	t.Run("protobuf round trip", func(t *testing.T) {
+		raw, err := marshalTransaction(expected, ProtobufEncoding)
+		require.NoError(t, err)
+		again, err := marshalTransaction(expected, ProtobufEncoding)
+		require.NoError(t, err)
+		require.Equal(t, raw, again)
+
+		actual := &Transaction{}
+		require.NoError(t, unmarshalTransaction(raw, actual))
+		requireSameTransaction(t, expected, actual)
+	})
+
+	t.Run("legacy json", func(t *testing.T) {
+		raw, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		actual := &Transaction{}
+		require.NoError(t, unmarshalTransaction(raw, actual))
+		requireSameTransaction(t, expected, actual)
+
+		raw, err = marshalTransaction(expected, JSONEncoding)
+		require.NoError(t, err)
+		actual = &Transaction{}
+		require.NoError(t, unmarshalTransaction(raw, actual))
+		requireSameTransaction(t, expected, actual)
+	})
+
+	t.Run("default readable by older releases", func(t *testing.T) {
+		raw, err := marshalTransaction(expected, "")
+		require.NoError(t, err)
+
+		// the transaction as decoded by the releases predating the protobuf wire format
+		baseline := &struct {
+			TCreator           view.Identity
+			TNonce             []byte
+			TTxID              string
+			TNetwork           string
+			TChannel           string
+			TChaincode         string
+			TChaincodeVersion  string
+			TFunction          string
+			TParameters        [][]byte
+			RWSet              []byte
+			TTransient         driver.TransientMap
+			TProposal          *pb.Proposal
+			TSignedProposal    *pb.SignedProposal
+			TProposalResponses []*pb.ProposalResponse
+		}{}
+		require.NoError(t, json.Unmarshal(raw, baseline))
+		require.Equal(t, expected.TCreator, baseline.TCreator)
+		require.Equal(t, expected.TNonce, baseline.TNonce)
+		require.Equal(t, expected.TTxID, baseline.TTxID)
+		require.Equal(t, expected.TNetwork, baseline.TNetwork)
+		require.Equal(t, expected.TChannel, baseline.TChannel)
+		require.Equal(t, expected.TChaincode, baseline.TChaincode)
+		require.Equal(t, expected.TChaincodeVersion, baseline.TChaincodeVersion)
+		require.Equal(t, expected.TFunction, baseline.TFunction)
+		require.Equal(t, expected.TParameters, baseline.TParameters)
+		require.Equal(t, expected.RWSet, baseline.RWSet)
+		require.Equal(t, expected.TTransient, baseline.TTransient)
+		require.True(t, proto.Equal(expected.TProposal, baseline.TProposal))
+		require.True(t, proto.Equal(expected.TSignedProposal, baseline.TSignedProposal))
+		require.Len(t, baseline.TProposalResponses, 1)
+		require.True(t, proto.Equal(expected.TProposalResponses[0], baseline.TProposalResponses[0]))
+
+		actual := &Transaction{}
+		require.NoError(t, unmarshalTransaction(raw, actual))
+		requireSameTransaction(t, expected, actual)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		raw, err := proto.Marshal(&protos.Transaction{Version: WireVersion + 1, TxId: "tx1"})
+		require.NoError(t, err)
+		require.Error(t, unmarshalTransaction(raw, &Transaction{}))
+
+		raw, err = proto.Marshal(&protos.Transaction{TxId: "tx1"})
+		require.NoError(t, err)
+		require.Error(t, unmarshalTransaction(raw, &Transaction{}))
+	})
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		_, err := marshalTransaction(expected, "xml")
+		require.Error(t, err)
+	})
+}
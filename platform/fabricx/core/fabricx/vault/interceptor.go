@@ -9,6 +9,7 @@ package vault
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync/atomic"
 
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/core/generic/vault"
@@ -17,35 +18,45 @@ import (
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
 )
 
+// ErrMetadataNotSupported is returned when setting the metadata of keys, as fabricx transactions cannot carry it.
+var ErrMetadataNotSupported = errors.New("key metadata not supported by fabricx")
+
 type Interceptor[V driver.ValidationCode] struct {
 	*vault.Interceptor[V]
 
-	txID driver.TxID
-	qe   vault.VersionedQueryExecutor
-	m    *Marshaller
+	ctx      context.Context
+	txID     driver.TxID
+	qe       vault.VersionedQueryExecutor
+	m        *Marshaller
+	versions NamespaceVersionResolver
 
 	// caches the serialized rwset
 	marshallingCache atomic.Pointer[[]byte]
 }
 
-func newInterceptor[V driver.ValidationCode](in *vault.Interceptor[V], txID driver.TxID, qe vault.VersionedQueryExecutor, m *Marshaller) *Interceptor[V] {
+func newInterceptor[V driver.ValidationCode](ctx context.Context, in *vault.Interceptor[V], txID driver.TxID, qe vault.VersionedQueryExecutor, m *Marshaller, versions NamespaceVersionResolver) *Interceptor[V] {
 	return &Interceptor[V]{
 		Interceptor: in,
+		ctx:         ctx,
 		txID:        txID,
 		qe:          qe,
 		m:           m,
+		versions:    versions,
 	}
 }
 
 func (i *Interceptor[V]) Bytes() ([]byte, error) {
 	if i.IsClosed() {
 		logger.Warnf("interceptor already closed!")
-		// TODO: we need to handle this case better; currently it only works when bytes was called before
-
-		return *i.marshallingCache.Load(), nil
+		// once closed, only the rwset serialized before closing is available
+		b := i.marshallingCache.Load()
+		if b == nil {
+			return nil, errors.New("this instance was closed before its rwset was serialized")
+		}
+		return *b, nil
 	}
 
-	nsInfo, err := namespaceVersions(i.qe, i.Namespaces()...)
+	nsInfo, err := i.versions.NamespaceVersions(i.ctx, i.qe, i.Namespaces()...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +71,18 @@ func (i *Interceptor[V]) Bytes() ([]byte, error) {
 	return b, nil
 }
 
+func (i *Interceptor[V]) SetStateMetadata(namespace string, key string, _ map[string][]byte) error {
+	return fmt.Errorf("key [%s] of namespace [%s]: %w", key, namespace, ErrMetadataNotSupported)
+}
+
+func (i *Interceptor[V]) SetStateMetadatas(namespace string, kvs map[string]map[string][]byte) map[string]error {
+	errs := make(map[string]error, len(kvs))
+	for key, value := range kvs {
+		errs[key] = i.SetStateMetadata(namespace, key, value)
+	}
+	return errs
+}
+
 func (i *Interceptor[V]) AppendRWSet(raw []byte, nss ...string) error {
 	if i.IsClosed() {
 		return errors.New("this instance was closed")
@@ -68,27 +91,32 @@ func (i *Interceptor[V]) AppendRWSet(raw []byte, nss ...string) error {
 	return i.m.Append(i.RWs(), raw, nss...)
 }
 
-func namespaceVersions(qe vault.VersionedQueryExecutor, namespaces ...string) (map[string][]byte, error) {
+// namespaceVersions reads the versions of the namespaces from the _meta namespace of the vault.
+// The namespaces missing there are reported with ErrUnknownNamespace.
+func namespaceVersions(ctx context.Context, qe vault.VersionedQueryExecutor, namespaces ...string) (map[string][]byte, error) {
 	nsInfo := make(map[string][]byte)
 
 	var errs error
 	for _, ns := range namespaces {
-		v, err := qe.GetState(context.TODO(), protoblocktx.MetaNamespace, ns)
+		// the _meta namespace does not list itself
+		if ns == protoblocktx.MetaNamespace {
+			nsInfo[ns] = types.VersionNumber(0).Bytes()
+			continue
+		}
+
+		v, err := qe.GetState(ctx, protoblocktx.MetaNamespace, ns)
 		if err != nil {
 			logger.Errorf("Ouch! error when reading %v-%v: %v", protoblocktx.MetaNamespace, ns, err)
 			errs = errors.Join(errs, err)
+			continue
 		}
-
 		if v == nil {
 			logger.Debugf("Ouch! %v-%v does not exist", protoblocktx.MetaNamespace, ns)
+			errs = errors.Join(errs, fmt.Errorf("namespace [%s] not found in [%s]: %w", ns, protoblocktx.MetaNamespace, ErrUnknownNamespace))
+			continue
 		}
 
-		nsVersion := types.VersionNumber(0).Bytes()
-		if v != nil {
-			nsVersion = v.Version
-		}
-
-		nsInfo[ns] = nsVersion
+		nsInfo[ns] = v.Version
 	}
 	if errs != nil {
 		return nil, errs
@@ -0,0 +1,43 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"context"
+	"testing"
+
+	vault2 "github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics/disabled"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInterceptorRejectsMetadata(t *testing.T) {
+	store, err := vault2.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	v := NewVault(vault2.NewCachedVault(store, 0), protoblocktx.NewMarshallerAdapter(), nil, &disabled.Provider{}, noop.NewTracerProvider())
+
+	rws, err := v.NewRWSet(context.Background(), "tx1")
+	require.NoError(t, err)
+	defer rws.Done()
+
+	require.ErrorIs(t, rws.SetStateMetadata("ns1", "key1", map[string][]byte{"a": []byte("b")}), ErrMetadataNotSupported)
+	batch, ok := rws.(interface {
+		SetStateMetadatas(string, map[string]map[string][]byte) map[string]error
+	})
+	require.True(t, ok)
+	errs := batch.SetStateMetadatas("ns1", map[string]map[string][]byte{"key1": {"a": []byte("b")}, "key2": nil})
+	require.Len(t, errs, 2)
+	for _, err := range errs {
+		require.ErrorIs(t, err, ErrMetadataNotSupported)
+	}
+	require.Empty(t, rws.Namespaces())
+
+	require.NoError(t, rws.SetState("ns1", "key1", []byte("value")))
+}
@@ -8,7 +8,8 @@ package vault
 
 import (
 	"encoding/json"
-	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/core/generic/vault"
@@ -20,6 +21,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// ErrUnknownNamespace is returned when the rwset accesses a namespace whose version is unknown.
+var ErrUnknownNamespace = errors.New("unknown namespace")
+
 // Marshaller is the custom marshaller for fabricx.
 type Marshaller struct {
 	adapter protoblocktx.Marshaller
@@ -62,19 +66,26 @@ func (m *Marshaller) marshal(txID string, rws *vault.ReadWriteSet, nsInfo map[dr
 
 	namespaceSet := make(map[driver.Namespace]*namespaceType)
 
-	// writes ...
-	for ns, keyMap := range rws.Writes {
+	// getNamespace returns the namespace, created if it does not exist yet
+	getNamespace := func(ns driver.Namespace) (*namespaceType, error) {
+		if namespace, exists := namespaceSet[ns]; exists {
+			return namespace, nil
+		}
 		// check that namespace exists as in _meta
 		nsVersion, exists := nsInfo[ns]
 		if !exists {
-			panic(fmt.Sprintf("ns = [%s] does not exist in nsInfo", ns))
+			return nil, errors.Wrapf(ErrUnknownNamespace, "no version for namespace [%s] of [%s]", ns, txID)
 		}
+		namespace := newNamespace(ns, nsVersion)
+		namespaceSet[ns] = namespace
+		return namespace, nil
+	}
 
-		// create namespace if not already exists
-		namespace, exists := namespaceSet[ns]
-		if !exists {
-			namespace = newNamespace(ns, nsVersion)
-			namespaceSet[ns] = namespace
+	// writes ...
+	for ns, keyMap := range rws.Writes {
+		namespace, err := getNamespace(ns)
+		if err != nil {
+			return nil, err
 		}
 
 		for key, val := range keyMap {
@@ -85,17 +96,9 @@ func (m *Marshaller) marshal(txID string, rws *vault.ReadWriteSet, nsInfo map[dr
 
 	// reads
 	for ns, keyMap := range rws.Reads {
-		// check that namespace exists as in _meta
-		nsVersion, exists := nsInfo[ns]
-		if !exists {
-			panic(fmt.Sprintf("ns = [%s] does not exist in nsInfo", ns))
-		}
-
-		// create namespace if not already exists
-		namespace, exists := namespaceSet[ns]
-		if !exists {
-			namespace = newNamespace(ns, nsVersion)
-			namespaceSet[ns] = namespace
+		namespace, err := getNamespace(ns)
+		if err != nil {
+			return nil, err
 		}
 
 		for key, ver := range keyMap {
@@ -111,21 +114,25 @@ func (m *Marshaller) marshal(txID string, rws *vault.ReadWriteSet, nsInfo map[dr
 		}
 	}
 
-	namespaces := make([]protoblocktx.TxNamespace, 0)
-	for _, namespace := range namespaceSet {
+	// namespaces and keys are sorted so that the same rwset always produces the same bytes,
+	// independent endorsers can then compare their results and combine their signatures
+	namespaces := make([]protoblocktx.TxNamespace, 0, len(namespaceSet))
+	for _, ns := range slices.Sorted(maps.Keys(namespaceSet)) {
+		namespace := namespaceSet[ns]
+
 		readsOnly := make([]protoblocktx.Read, 0, len(namespace.readSet))
-		for _, read := range namespace.readSet {
-			readsOnly = append(readsOnly, read)
+		for _, key := range slices.Sorted(maps.Keys(namespace.readSet)) {
+			readsOnly = append(readsOnly, namespace.readSet[key])
 		}
 
 		blindWrites := make([]protoblocktx.Write, 0, len(namespace.writeSet))
-		for _, write := range namespace.writeSet {
-			blindWrites = append(blindWrites, write)
+		for _, key := range slices.Sorted(maps.Keys(namespace.writeSet)) {
+			blindWrites = append(blindWrites, namespace.writeSet[key])
 		}
 
 		readWrites := make([]protoblocktx.ReadWrite, 0, len(namespace.readWriteSet))
-		for _, readWrite := range namespace.readWriteSet {
-			readWrites = append(readWrites, readWrite)
+		for _, key := range slices.Sorted(maps.Keys(namespace.readWriteSet)) {
+			readWrites = append(readWrites, namespace.readWriteSet[key])
 		}
 
 		namespaces = append(namespaces, protoblocktx.NewTxNamespace(namespace.ns, namespace.nsVersion, readsOnly, readWrites, blindWrites))
@@ -137,11 +144,6 @@ func (m *Marshaller) marshal(txID string, rws *vault.ReadWriteSet, nsInfo map[dr
 		logger.Debugf("fabricx transaction: %s", str)
 	}
 
-	if logger.IsEnabledFor(zap.DebugLevel) {
-		str, _ := json.MarshalIndent(txIn, "", "\t")
-		logger.Debugf("Unmarshalled fabricx tx %s", string(str))
-	}
-
 	raw, err := m.adapter.MarshalTx(txIn)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/core/generic/vault"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalIsDeterministic(t *testing.T) {
+	m := NewMarshaller(protoblocktx.NewMarshallerAdapter())
+
+	rws := vault.EmptyRWSet()
+	nsInfo := map[driver.Namespace]driver.RawVersion{}
+	for _, ns := range []string{"ns3", "ns1", "ns2"} {
+		nsInfo[ns] = Marshal(1)
+		for i := range 10 {
+			rws.ReadSet.Add(ns, fmt.Sprintf("read%d", i), Marshal(uint32(i)))
+			require.NoError(t, rws.WriteSet.Add(ns, fmt.Sprintf("write%d", i), []byte("value")))
+			rws.ReadSet.Add(ns, fmt.Sprintf("rw%d", i), Marshal(uint32(i)))
+			require.NoError(t, rws.WriteSet.Add(ns, fmt.Sprintf("rw%d", i), []byte("value")))
+		}
+	}
+
+	expected, err := m.marshal("tx1", &rws, nsInfo)
+	require.NoError(t, err)
+	for range 20 {
+		raw, err := m.marshal("tx1", &rws, nsInfo)
+		require.NoError(t, err)
+		require.Equal(t, expected, raw)
+	}
+
+	tx, err := protoblocktx.NewMarshallerAdapter().UnmarshalTx(expected)
+	require.NoError(t, err)
+	require.Len(t, tx.GetNamespaces(), 3)
+	for i, ns := range tx.GetNamespaces() {
+		require.Equal(t, fmt.Sprintf("ns%d", i+1), ns.GetNsId())
+		require.Len(t, ns.GetReadsOnly(), 10)
+		require.Len(t, ns.GetReadWrites(), 10)
+		require.Len(t, ns.GetBlindWrites(), 10)
+		require.IsIncreasing(t, keys(ns.GetReadsOnly()))
+		require.IsIncreasing(t, keys(ns.GetReadWrites()))
+		require.IsIncreasing(t, keys(ns.GetBlindWrites()))
+	}
+}
+
+func TestMarshalUnknownNamespace(t *testing.T) {
+	m := NewMarshaller(protoblocktx.NewMarshallerAdapter())
+
+	rws := vault.EmptyRWSet()
+	rws.ReadSet.Add("ns1", "key1", Marshal(1))
+	require.NoError(t, rws.WriteSet.Add("ns2", "key2", []byte("value")))
+
+	_, err := m.marshal("tx1", &rws, map[driver.Namespace]driver.RawVersion{"ns1": Marshal(1)})
+	require.ErrorIs(t, err, ErrUnknownNamespace)
+	require.ErrorContains(t, err, "ns2")
+}
+
+func keys[T interface{ GetKey() []byte }](items []T) []string {
+	res := make([]string, len(items))
+	for i, item := range items {
+		res[i] = string(item.GetKey())
+	}
+	return res
+}
@@ -0,0 +1,145 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/core/generic/vault"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	vault2 "github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
+)
+
+// NamespaceVersionResolver returns the versions of the namespaces a transaction accesses.
+// qe is the query executor of the transaction.
+type NamespaceVersionResolver interface {
+	NamespaceVersions(ctx context.Context, qe vault.VersionedQueryExecutor, namespaces ...driver.Namespace) (map[driver.Namespace]driver.RawVersion, error)
+}
+
+// NamespaceVersionSource lists the versions of the namespaces known to the committer.
+type NamespaceVersionSource interface {
+	GetNamespaceVersions(ctx context.Context) (map[driver.Namespace]driver.RawVersion, error)
+}
+
+// localNamespaceVersions reads the versions from the _meta namespace of the local vault.
+// A namespace missing locally is reported with ErrUnknownNamespace.
+type localNamespaceVersions struct{}
+
+func (localNamespaceVersions) NamespaceVersions(ctx context.Context, qe vault.VersionedQueryExecutor, namespaces ...driver.Namespace) (map[driver.Namespace]driver.RawVersion, error) {
+	return namespaceVersions(ctx, qe, namespaces...)
+}
+
+// NewNamespaceVersionCache returns a resolver serving the versions of the committer from a cache.
+// The cache is refreshed when a namespace is missing, and dropped by Invalidate.
+// Committers that do not serve the versions are answered from the local vault, as localNamespaceVersions.
+func NewNamespaceVersionCache(source NamespaceVersionSource) *namespaceVersionCache {
+	return &namespaceVersionCache{source: source}
+}
+
+type namespaceVersionCache struct {
+	source NamespaceVersionSource
+
+	mu       sync.RWMutex
+	versions map[driver.Namespace]driver.RawVersion
+	// generation is increased by Invalidate, so that a refresh racing with it is discarded
+	generation uint64
+}
+
+func (c *namespaceVersionCache) NamespaceVersions(ctx context.Context, qe vault.VersionedQueryExecutor, namespaces ...driver.Namespace) (map[driver.Namespace]driver.RawVersion, error) {
+	if res, ok := c.lookup(namespaces); ok {
+		return res, nil
+	}
+
+	c.mu.RLock()
+	generation := c.generation
+	c.mu.RUnlock()
+	versions, err := c.source.GetNamespaceVersions(ctx)
+	if errors.Is(err, protoqueryservice.ErrNotSupported) {
+		logger.Debugf("committer does not serve namespace versions, read them locally")
+		return namespaceVersions(ctx, qe, namespaces...)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed getting namespace versions")
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.versions = versions
+	}
+	c.mu.Unlock()
+
+	res := make(map[driver.Namespace]driver.RawVersion, len(namespaces))
+	for _, ns := range namespaces {
+		v, ok := namespaceVersion(versions, ns)
+		if !ok {
+			return nil, errors.Wrapf(ErrUnknownNamespace, "namespace [%s] is not known to the committer", ns)
+		}
+		res[ns] = v
+	}
+	return res, nil
+}
+
+func (c *namespaceVersionCache) lookup(namespaces []driver.Namespace) (map[driver.Namespace]driver.RawVersion, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.versions == nil {
+		return nil, false
+	}
+	res := make(map[driver.Namespace]driver.RawVersion, len(namespaces))
+	for _, ns := range namespaces {
+		v, ok := namespaceVersion(c.versions, ns)
+		if !ok {
+			return nil, false
+		}
+		res[ns] = v
+	}
+	return res, true
+}
+
+// Invalidate drops the cached versions.
+func (c *namespaceVersionCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions = nil
+	c.generation++
+}
+
+func namespaceVersion(versions map[driver.Namespace]driver.RawVersion, ns driver.Namespace) (driver.RawVersion, bool) {
+	// the committer does not list the _meta namespace among the policies
+	if ns == protoblocktx.MetaNamespace {
+		return types.VersionNumber(0).Bytes(), true
+	}
+	v, ok := versions[ns]
+	return v, ok
+}
+
+// NewNamespaceVersionInvalidator wraps the vault store so that the cache is invalidated
+// whenever a committed transaction writes to the _meta namespace, that is, creates or updates a namespace.
+func NewNamespaceVersionInvalidator(store vault2.CachedVaultStore, cache *namespaceVersionCache) *namespaceVersionInvalidator {
+	return &namespaceVersionInvalidator{CachedVaultStore: store, cache: cache}
+}
+
+type namespaceVersionInvalidator struct {
+	vault2.CachedVaultStore
+	cache *namespaceVersionCache
+}
+
+func (s *namespaceVersionInvalidator) Store(ctx context.Context, txIDs []driver.TxID, writes driver.Writes, metaWrites driver.MetaWrites) error {
+	if err := s.CachedVaultStore.Store(ctx, txIDs, writes, metaWrites); err != nil {
+		return err
+	}
+	if len(writes[protoblocktx.MetaNamespace]) != 0 {
+		logger.Debugf("namespaces updated by [%v], invalidate namespace versions", txIDs)
+		s.cache.Invalidate()
+	}
+	return nil
+}
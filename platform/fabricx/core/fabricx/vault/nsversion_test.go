@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	vault2 "github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+type versionSource struct {
+	versions map[driver.Namespace]driver.RawVersion
+	err      error
+	calls    int
+}
+
+func (s *versionSource) GetNamespaceVersions(context.Context) (map[driver.Namespace]driver.RawVersion, error) {
+	s.calls++
+	return s.versions, s.err
+}
+
+type metaQueryExecutor struct {
+	versions map[driver.PKey]driver.RawVersion
+}
+
+func (q *metaQueryExecutor) GetStateMetadata(context.Context, driver.Namespace, driver.PKey) (driver.Metadata, driver.RawVersion, error) {
+	return nil, nil, nil
+}
+
+func (q *metaQueryExecutor) GetState(_ context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultRead, error) {
+	v, ok := q.versions[key]
+	if ns != protoblocktx.MetaNamespace || !ok {
+		return nil, nil
+	}
+	return &driver.VaultRead{Key: key, Version: v}, nil
+}
+
+func (q *metaQueryExecutor) Done() error { return nil }
+
+func TestNamespaceVersionCache(t *testing.T) {
+	ctx := context.Background()
+	source := &versionSource{versions: map[driver.Namespace]driver.RawVersion{"ns1": types.VersionNumber(2).Bytes()}}
+	cache := NewNamespaceVersionCache(source)
+
+	versions, err := cache.NamespaceVersions(ctx, nil, "ns1", protoblocktx.MetaNamespace)
+	require.NoError(t, err)
+	require.Equal(t, map[driver.Namespace]driver.RawVersion{
+		"ns1":                      types.VersionNumber(2).Bytes(),
+		protoblocktx.MetaNamespace: types.VersionNumber(0).Bytes(),
+	}, versions)
+	require.Equal(t, 1, source.calls)
+
+	// served from the cache
+	_, err = cache.NamespaceVersions(ctx, nil, "ns1")
+	require.NoError(t, err)
+	require.Equal(t, 1, source.calls)
+
+	// a missing namespace refreshes the cache, and fails if the committer does not know it either
+	_, err = cache.NamespaceVersions(ctx, nil, "ns2")
+	require.ErrorIs(t, err, ErrUnknownNamespace)
+	require.Equal(t, 2, source.calls)
+
+	source.versions = map[driver.Namespace]driver.RawVersion{
+		"ns1": types.VersionNumber(2).Bytes(),
+		"ns2": types.VersionNumber(1).Bytes(),
+	}
+	versions, err = cache.NamespaceVersions(ctx, nil, "ns2")
+	require.NoError(t, err)
+	require.Equal(t, types.VersionNumber(1).Bytes(), versions["ns2"])
+	require.Equal(t, 3, source.calls)
+}
+
+func TestNamespaceVersionCacheNotSupported(t *testing.T) {
+	source := &versionSource{err: protoqueryservice.ErrNotSupported}
+	qe := &metaQueryExecutor{versions: map[driver.PKey]driver.RawVersion{"ns1": types.VersionNumber(4).Bytes()}}
+
+	cache := NewNamespaceVersionCache(source)
+	versions, err := cache.NamespaceVersions(context.Background(), qe, "ns1", protoblocktx.MetaNamespace)
+	require.NoError(t, err)
+	require.Equal(t, map[driver.Namespace]driver.RawVersion{
+		"ns1":                      types.VersionNumber(4).Bytes(),
+		protoblocktx.MetaNamespace: types.VersionNumber(0).Bytes(),
+	}, versions)
+
+	// the namespaces missing in the local vault are not given a version
+	_, err = cache.NamespaceVersions(context.Background(), qe, "ns1", "ns2")
+	require.ErrorIs(t, err, ErrUnknownNamespace)
+	_, err = localNamespaceVersions{}.NamespaceVersions(context.Background(), qe, "ns2")
+	require.ErrorIs(t, err, ErrUnknownNamespace)
+}
+
+func TestNamespaceVersionInvalidator(t *testing.T) {
+	ctx := context.Background()
+	store, err := vault2.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	source := &versionSource{versions: map[driver.Namespace]driver.RawVersion{"ns1": types.VersionNumber(1).Bytes()}}
+	cache := NewNamespaceVersionCache(source)
+	invalidator := NewNamespaceVersionInvalidator(vault2.NewCachedVault(store, 0), cache)
+
+	_, err = cache.NamespaceVersions(ctx, nil, "ns1")
+	require.NoError(t, err)
+	require.Equal(t, 1, source.calls)
+
+	// regular writes keep the cache
+	err = invalidator.Store(ctx, []driver.TxID{"tx1"}, driver.Writes{
+		"ns1": {"key1": {Raw: []byte("value1"), Version: types.VersionNumber(1).Bytes()}},
+	}, nil)
+	require.NoError(t, err)
+	_, err = cache.NamespaceVersions(ctx, nil, "ns1")
+	require.NoError(t, err)
+	require.Equal(t, 1, source.calls)
+
+	// a namespace update drops it
+	source.versions = map[driver.Namespace]driver.RawVersion{"ns1": types.VersionNumber(2).Bytes()}
+	err = invalidator.Store(ctx, []driver.TxID{"tx2"}, driver.Writes{
+		protoblocktx.MetaNamespace: {"ns1": {Raw: []byte("policy"), Version: types.VersionNumber(2).Bytes()}},
+	}, nil)
+	require.NoError(t, err)
+	versions, err := cache.NamespaceVersions(ctx, nil, "ns1")
+	require.NoError(t, err)
+	require.Equal(t, types.VersionNumber(2).Bytes(), versions["ns1"])
+	require.Equal(t, 2, source.calls)
+}
@@ -0,0 +1,329 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// The load balancing policies of the configuration.
+const (
+	RoundRobin   = "roundRobin"
+	LeastLatency = "leastLatency"
+)
+
+// latencyWeight is the weight of the last call in the moving average of the latency of a replica
+const latencyWeight = 0.2
+
+// Connection reports the state of the connection to a replica.
+// *grpc.ClientConn implements it.
+type Connection interface {
+	GetState() connectivity.State
+	WaitForStateChange(ctx context.Context, sourceState connectivity.State) bool
+	Connect()
+}
+
+// Replica is one of the query service endpoints of the committer.
+type Replica struct {
+	Address string
+	Client  protoqueryservice.QueryServiceClient
+	// Conn is nil when the state of the connection is unknown
+	Conn Connection
+}
+
+// NewBalancer returns a client that spreads the calls over the replicas according to the configured policy.
+// A call failing because its replica is unavailable is retried on the next replica,
+// and the failed replica is skipped until the failure backoff has elapsed.
+// When no replica is healthy, all are tried anyway, but the ones whose circuit breaker is open.
+// The connections are established eagerly and watched until Close, so that their health is known before the first call.
+func NewBalancer(config LoadBalancingConfig, replicas []Replica, metrics *Metrics) *Balancer {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Balancer{
+		policy:         config.Policy,
+		failureBackoff: config.FailureBackoff,
+		metrics:        metrics,
+		replicas:       make([]*replica, len(replicas)),
+		cancel:         cancel,
+	}
+	for i, r := range replicas {
+		b.replicas[i] = &replica{Replica: r, circuit: circuit{config: config.CircuitBreaker}}
+		b.report(b.replicas[i])
+		if r.Conn != nil {
+			go b.watch(ctx, b.replicas[i])
+		}
+	}
+	return b
+}
+
+type Balancer struct {
+	policy         string
+	failureBackoff time.Duration
+	metrics        *Metrics
+	replicas       []*replica
+	next           atomic.Uint64
+	cancel         context.CancelFunc
+}
+
+// Close stops watching the connections. It does not close them.
+func (b *Balancer) Close() {
+	b.cancel()
+}
+
+// watch reports the health of the replica whenever the state of its connection changes.
+func (b *Balancer) watch(ctx context.Context, r *replica) {
+	r.Conn.Connect()
+	state := r.Conn.GetState()
+	for r.Conn.WaitForStateChange(ctx, state) {
+		state = r.Conn.GetState()
+		logger.Debugf("query service [%s] is [%s]", r.Address, state)
+		if state == connectivity.Idle {
+			// keep the connection up, so that failures are detected before the calls
+			r.Conn.Connect()
+		}
+		b.report(r)
+	}
+}
+
+type replica struct {
+	Replica
+
+	mu sync.RWMutex
+	// failedUntil is the time until which the replica is considered down after a failure
+	failedUntil time.Time
+	// latency is the moving average of the latency of the successful calls, zero until the first one
+	latency time.Duration
+	circuit circuit
+}
+
+// EndpointHealth is the health of a replica, as seen by the balancer.
+type EndpointHealth struct {
+	Address string
+	Healthy bool
+	Latency time.Duration
+	Circuit CircuitState
+}
+
+// Health returns the health of the replicas, in the order of the configuration.
+func (b *Balancer) Health() []EndpointHealth {
+	health := make([]EndpointHealth, len(b.replicas))
+	for i, r := range b.replicas {
+		now := time.Now()
+		r.mu.RLock()
+		health[i] = EndpointHealth{Address: r.Address, Healthy: r.healthy(now), Latency: r.latency, Circuit: r.circuit.current(now)}
+		r.mu.RUnlock()
+	}
+	return health
+}
+
+// Pin returns a client sending all the calls to the same replica.
+// Views only exist on the replica that began them, hence the calls of a view must be pinned.
+func (b *Balancer) Pin() protoqueryservice.QueryServiceClient {
+	return &pinnedClient{balancer: b, replica: b.candidates()[0]}
+}
+
+func (b *Balancer) GetRows(ctx context.Context, in protoqueryservice.Query, opts ...grpc.CallOption) (protoqueryservice.Rows, error) {
+	return call(ctx, b, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.Rows, error) {
+		return c.GetRows(ctx, in, opts...)
+	})
+}
+
+func (b *Balancer) BeginView(ctx context.Context, in protoqueryservice.ViewParameters, opts ...grpc.CallOption) (protoqueryservice.View, error) {
+	return call(ctx, b, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.View, error) {
+		return c.BeginView(ctx, in, opts...)
+	})
+}
+
+func (b *Balancer) EndView(ctx context.Context, in protoqueryservice.View, opts ...grpc.CallOption) (protoqueryservice.View, error) {
+	return call(ctx, b, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.View, error) {
+		return c.EndView(ctx, in, opts...)
+	})
+}
+
+func (b *Balancer) GetPolicies(ctx context.Context, opts ...grpc.CallOption) (protoqueryservice.Policies, error) {
+	return call(ctx, b, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.Policies, error) {
+		return c.GetPolicies(ctx, opts...)
+	})
+}
+
+func (b *Balancer) GetRowsRange(ctx context.Context, in protoqueryservice.RangeQuery, opts ...grpc.CallOption) (protoqueryservice.RowsStream, error) {
+	return call(ctx, b, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.RowsStream, error) {
+		return c.GetRowsRange(ctx, in, opts...)
+	})
+}
+
+// call tries the replicas in the order of the policy, until one is available.
+// It fails with ErrCircuitOpen if the circuits of all the replicas are open.
+func call[T any](ctx context.Context, b *Balancer, f func(protoqueryservice.QueryServiceClient) (T, error)) (T, error) {
+	var res T
+	err := ErrCircuitOpen
+	for _, r := range b.candidates() {
+		probe, ok := r.allow()
+		if !ok {
+			continue
+		}
+		res, err = invoke(b, r, probe, f)
+		if !unavailable(err) || ctx.Err() != nil {
+			return res, err
+		}
+		logger.Warnf("query service [%s] unavailable, fail over: %v", r.Address, err)
+	}
+	return res, err
+}
+
+// invoke calls the replica, and updates its health, latency and circuit with the outcome.
+// The circuit must have allowed the call, and probe is what it returned.
+func invoke[T any](b *Balancer, r *replica, probe bool, f func(protoqueryservice.QueryServiceClient) (T, error)) (T, error) {
+	start := time.Now()
+	res, err := f(r.Client)
+	elapsed := time.Since(start)
+
+	r.mu.Lock()
+	if r.circuit.record(time.Now(), err, probe) {
+		logger.Warnf("query service [%s] circuit open for %v after [%d] failures", r.Address, r.circuit.config.OpenTimeout, r.circuit.failures)
+	}
+	switch {
+	case unavailable(err):
+		r.failedUntil = time.Now().Add(b.failureBackoff)
+	case err == nil:
+		r.failedUntil = time.Time{}
+		if r.latency == 0 {
+			r.latency = elapsed
+		} else {
+			r.latency = time.Duration(latencyWeight*float64(elapsed) + (1-latencyWeight)*float64(r.latency))
+		}
+	}
+	r.mu.Unlock()
+
+	b.metrics.observe(r.Address, elapsed, err)
+	b.report(r)
+	return res, err
+}
+
+// report exports the health and the circuit state of the replica
+func (b *Balancer) report(r *replica) {
+	now := time.Now()
+	r.mu.RLock()
+	healthy := r.healthy(now)
+	state := r.circuit.current(now)
+	r.mu.RUnlock()
+	b.metrics.setHealthy(r.Address, healthy)
+	b.metrics.setCircuitState(r.Address, state)
+}
+
+// candidates returns the healthy replicas in the order of the policy, followed by the unhealthy ones.
+func (b *Balancer) candidates() []*replica {
+	now := time.Now()
+	healthy := make([]*replica, 0, len(b.replicas))
+	var unhealthy []*replica
+	for _, r := range b.replicas {
+		r.mu.RLock()
+		ok := r.healthy(now)
+		r.mu.RUnlock()
+		if ok {
+			healthy = append(healthy, r)
+		} else {
+			unhealthy = append(unhealthy, r)
+		}
+	}
+
+	switch b.policy {
+	case LeastLatency:
+		// replicas without latency yet come first, so that all get measured
+		slices.SortStableFunc(healthy, func(x, y *replica) int { return cmp.Compare(x.averageLatency(), y.averageLatency()) })
+	default:
+		if len(healthy) > 1 {
+			start := int((b.next.Add(1) - 1) % uint64(len(healthy))) //nolint:gosec
+			healthy = slices.Concat(healthy[start:], healthy[:start])
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (r *replica) healthy(now time.Time) bool {
+	if now.Before(r.failedUntil) || r.circuit.current(now) == CircuitOpen {
+		return false
+	}
+	if r.Conn == nil {
+		return true
+	}
+	state := r.Conn.GetState()
+	return state != connectivity.TransientFailure && state != connectivity.Shutdown
+}
+
+// allow tells whether the circuit of the replica lets a call through, and whether the call is its probe
+func (r *replica) allow() (probe bool, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.circuit.allow(time.Now())
+}
+
+func (r *replica) averageLatency() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.latency
+}
+
+func unavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// pinnedClient sends all the calls to a single replica, without failover.
+type pinnedClient struct {
+	balancer *Balancer
+	replica  *replica
+}
+
+// invokePinned calls the pinned replica, unless its circuit is open.
+func invokePinned[T any](c *pinnedClient, f func(protoqueryservice.QueryServiceClient) (T, error)) (T, error) {
+	probe, ok := c.replica.allow()
+	if !ok {
+		var zero T
+		return zero, ErrCircuitOpen
+	}
+	return invoke(c.balancer, c.replica, probe, f)
+}
+
+func (c *pinnedClient) GetRows(ctx context.Context, in protoqueryservice.Query, opts ...grpc.CallOption) (protoqueryservice.Rows, error) {
+	return invokePinned(c, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.Rows, error) {
+		return c.GetRows(ctx, in, opts...)
+	})
+}
+
+func (c *pinnedClient) BeginView(ctx context.Context, in protoqueryservice.ViewParameters, opts ...grpc.CallOption) (protoqueryservice.View, error) {
+	return invokePinned(c, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.View, error) {
+		return c.BeginView(ctx, in, opts...)
+	})
+}
+
+func (c *pinnedClient) EndView(ctx context.Context, in protoqueryservice.View, opts ...grpc.CallOption) (protoqueryservice.View, error) {
+	return invokePinned(c, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.View, error) {
+		return c.EndView(ctx, in, opts...)
+	})
+}
+
+func (c *pinnedClient) GetPolicies(ctx context.Context, opts ...grpc.CallOption) (protoqueryservice.Policies, error) {
+	return invokePinned(c, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.Policies, error) {
+		return c.GetPolicies(ctx, opts...)
+	})
+}
+
+func (c *pinnedClient) GetRowsRange(ctx context.Context, in protoqueryservice.RangeQuery, opts ...grpc.CallOption) (protoqueryservice.RowsStream, error) {
+	return invokePinned(c, func(c protoqueryservice.QueryServiceClient) (protoqueryservice.RowsStream, error) {
+		return c.GetRowsRange(ctx, in, opts...)
+	})
+}
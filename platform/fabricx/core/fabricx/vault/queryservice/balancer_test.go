@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics/disabled"
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnavailable = status.Error(codes.Unavailable, "connection refused")
+
+func setupBalancer(t *testing.T, policy string, n int) (*queryservice.Balancer, []*protoqueryservicefakes.FakeQueryServiceClient) {
+	t.Helper()
+	return setupBalancerConfig(t, queryservice.LoadBalancingConfig{Policy: policy, FailureBackoff: time.Minute}, n)
+}
+
+func setupBalancerConfig(t *testing.T, config queryservice.LoadBalancingConfig, n int) (*queryservice.Balancer, []*protoqueryservicefakes.FakeQueryServiceClient) {
+	t.Helper()
+	fakes := make([]*protoqueryservicefakes.FakeQueryServiceClient, n)
+	replicas := make([]queryservice.Replica, n)
+	for i := range fakes {
+		fakes[i] = &protoqueryservicefakes.FakeQueryServiceClient{}
+		fakes[i].GetRowsReturns(&protoqueryservice.Rows{}, nil)
+		replicas[i] = queryservice.Replica{
+			Address: fmt.Sprintf("replica%d:7001", i),
+			Client:  protoqueryservice.NewServiceAdapter(fakes[i]),
+		}
+	}
+	b := queryservice.NewBalancer(config, replicas, queryservice.NewMetrics(&disabled.Provider{}))
+	t.Cleanup(b.Close)
+	return b, fakes
+}
+
+func getRows(t *testing.T, b *queryservice.Balancer) error {
+	t.Helper()
+	_, err := b.GetRows(context.Background(), api.NewQuery(nil, []api.QueryNamespace{api.NewQueryNamespace("ns1", [][]byte{[]byte("key1")})}))
+	return err
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.RoundRobin, 3)
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, getRows(t, b))
+	}
+	for _, fake := range fakes {
+		require.Equal(t, 2, fake.GetRowsCallCount())
+	}
+}
+
+func TestBalancerFailover(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.RoundRobin, 2)
+	fakes[0].GetRowsReturns(nil, errUnavailable)
+
+	// the first call fails over to the second replica
+	require.NoError(t, getRows(t, b))
+	require.Equal(t, 1, fakes[0].GetRowsCallCount())
+	require.Equal(t, 1, fakes[1].GetRowsCallCount())
+
+	health := b.Health()
+	require.Equal(t, "replica0:7001", health[0].Address)
+	require.False(t, health[0].Healthy)
+	require.True(t, health[1].Healthy)
+
+	// the failed replica is skipped afterwards
+	for i := 0; i < 3; i++ {
+		require.NoError(t, getRows(t, b))
+	}
+	require.Equal(t, 1, fakes[0].GetRowsCallCount())
+	require.Equal(t, 4, fakes[1].GetRowsCallCount())
+}
+
+func TestBalancerAllUnavailable(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.RoundRobin, 2)
+	fakes[0].GetRowsReturns(nil, errUnavailable)
+	fakes[1].GetRowsReturns(nil, errUnavailable)
+
+	require.Equal(t, codes.Unavailable, status.Code(getRows(t, b)))
+
+	// unhealthy replicas are still tried when no replica is healthy
+	fakes[1].GetRowsReturns(&protoqueryservice.Rows{}, nil)
+	require.NoError(t, getRows(t, b))
+	require.True(t, b.Health()[1].Healthy)
+}
+
+func TestBalancerNoFailoverOnOtherErrors(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.RoundRobin, 2)
+	fakes[0].GetRowsReturns(nil, errors.New("invalid query"))
+
+	require.EqualError(t, getRows(t, b), "invalid query")
+	require.Equal(t, 0, fakes[1].GetRowsCallCount())
+	require.True(t, b.Health()[0].Healthy)
+}
+
+func TestBalancerLeastLatency(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.LeastLatency, 2)
+	fakes[0].GetRowsStub = func(context.Context, *protoqueryservice.Query, ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &protoqueryservice.Rows{}, nil
+	}
+
+	// both replicas are measured first, then the fastest one is preferred
+	for i := 0; i < 5; i++ {
+		require.NoError(t, getRows(t, b))
+	}
+	require.Equal(t, 1, fakes[0].GetRowsCallCount())
+	require.Equal(t, 4, fakes[1].GetRowsCallCount())
+	require.Greater(t, b.Health()[0].Latency, b.Health()[1].Latency)
+}
+
+func TestBalancerPinsSessions(t *testing.T) {
+	b, fakes := setupBalancer(t, queryservice.RoundRobin, 2)
+	for _, fake := range fakes {
+		fake.BeginViewReturns(&protoqueryservice.View{Id: "view1"}, nil)
+		fake.EndViewReturns(&protoqueryservice.View{Id: "view1"}, nil)
+	}
+	config := &queryservice.Config{QueryTimeout: 5 * time.Second, View: queryservice.ViewConfig{Enabled: true}}
+	qs := queryservice.NewRemoteQueryService(config, b)
+
+	s := qs.NewSession(driver.LevelDefault)
+	for i := 0; i < 3; i++ {
+		_, err := s.GetState("ns1", "key1")
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+
+	// the view is begun, queried and ended on the same replica
+	pinned, other := fakes[0], fakes[1]
+	if pinned.BeginViewCallCount() == 0 {
+		pinned, other = other, pinned
+	}
+	require.Equal(t, 1, pinned.BeginViewCallCount())
+	require.Equal(t, 3, pinned.GetRowsCallCount())
+	require.Equal(t, 1, pinned.EndViewCallCount())
+	require.Equal(t, 0, other.BeginViewCallCount()+other.GetRowsCallCount()+other.EndViewCallCount())
+}
@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryFunc func(context.Context, map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error)
+
+// newBatcher returns a batcher coalescing the lookups into queries of up to maxKeys keys,
+// sent at most maxWait after their first lookup.
+func newBatcher(query queryFunc, maxWait time.Duration, maxKeys int) *batcher {
+	return &batcher{query: query, maxWait: maxWait, maxKeys: maxKeys}
+}
+
+// batcher gathers the concurrent lookups of single keys, so that a single query answers them all.
+type batcher struct {
+	query   queryFunc
+	maxWait time.Duration
+	maxKeys int
+
+	mu sync.Mutex
+	// pending is the batch collecting the lookups, nil if there is none
+	pending *batch
+}
+
+type batch struct {
+	keys  map[driver.Namespace]map[driver.PKey]struct{}
+	size  int
+	timer *time.Timer
+	// spanCtx is the span of the first caller having one, the parent of the query
+	spanCtx trace.SpanContext
+	// deadline is the earliest deadline of the callers, zero if none has one
+	deadline time.Time
+	// done is closed when res and err are set
+	done chan struct{}
+	res  map[driver.Namespace]map[driver.PKey]driver.VaultValue
+	err  error
+}
+
+// get adds the key to the pending batch, and returns its value once the batch is answered.
+// The query of the batch is traced under the span of its first caller, and bounded by the earliest deadline
+// of its callers. Cancelling the context only stops the wait: the batch is sent anyway for the other callers.
+func (b *batcher) get(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	b.mu.Lock()
+	p := b.pending
+	if p == nil {
+		p = &batch{keys: map[driver.Namespace]map[driver.PKey]struct{}{}, done: make(chan struct{})}
+		p.timer = time.AfterFunc(b.maxWait, func() { b.flush(p) })
+		b.pending = p
+	}
+	if _, ok := p.keys[ns]; !ok {
+		p.keys[ns] = map[driver.PKey]struct{}{}
+	}
+	if _, ok := p.keys[ns][key]; !ok {
+		p.keys[ns][key] = struct{}{}
+		p.size++
+	}
+	if !p.spanCtx.IsValid() {
+		p.spanCtx = trace.SpanContextFromContext(ctx)
+	}
+	if deadline, ok := ctx.Deadline(); ok && (p.deadline.IsZero() || deadline.Before(p.deadline)) {
+		p.deadline = deadline
+	}
+	full := p.size >= b.maxKeys
+	if full {
+		// the timer may have fired already, flush then finds that the batch is no longer pending
+		p.timer.Stop()
+		b.pending = nil
+	}
+	b.mu.Unlock()
+
+	if full {
+		b.send(p)
+	}
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return lookup(p.res, ns, key), nil
+}
+
+// flush sends the batch if it is still pending.
+func (b *batcher) flush(p *batch) {
+	b.mu.Lock()
+	if b.pending != p {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+
+	b.send(p)
+}
+
+func (b *batcher) send(p *batch) {
+	m := make(map[driver.Namespace][]driver.PKey, len(p.keys))
+	for ns, keys := range p.keys {
+		m[ns] = make([]driver.PKey, 0, len(keys))
+		for key := range keys {
+			m[ns] = append(m[ns], key)
+		}
+	}
+	ctx := context.Background()
+	if p.spanCtx.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, p.spanCtx)
+	}
+	if !p.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, p.deadline)
+		defer cancel()
+	}
+	logger.Debugf("QS send batch of [%d] keys", p.size)
+	p.res, p.err = b.query(ctx, m)
+	close(p.done)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// echoRows answers each key of the query with a row, but the ones starting with "missing"
+func echoRows(_ context.Context, q *protoqueryservice.Query, _ ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+	rows := &protoqueryservice.Rows{}
+	for _, ns := range q.GetNamespaces() {
+		rowsNs := &protoqueryservice.RowsNamespace{NsId: ns.GetNsId()}
+		for _, key := range ns.GetKeys() {
+			if len(key) >= 7 && string(key[:7]) == "missing" {
+				continue
+			}
+			rowsNs.Rows = append(rowsNs.Rows, row(string(key), 1))
+		}
+		rows.Namespaces = append(rows.Namespaces, rowsNs)
+	}
+	return rows, nil
+}
+
+func setupBatchTest(batching queryservice.BatchingConfig) (*queryservice.RemoteQueryService, *protoqueryservicefakes.FakeQueryServiceClient) {
+	client := &protoqueryservicefakes.FakeQueryServiceClient{}
+	client.GetRowsStub = echoRows
+	batching.Enabled = true
+	config := &queryservice.Config{QueryTimeout: 5 * time.Second, Batching: batching}
+	return queryservice.NewRemoteQueryService(config, protoqueryservice.NewServiceAdapter(client)), client
+}
+
+// getStates looks up the keys concurrently
+func getStates(qs queryservice.QueryService, keys map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]*driver.VaultValue, error) {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	res := map[driver.Namespace]map[driver.PKey]*driver.VaultValue{}
+	for ns, nsKeys := range keys {
+		res[ns] = map[driver.PKey]*driver.VaultValue{}
+		for _, key := range nsKeys {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := qs.GetState(ns, key)
+				mu.Lock()
+				defer mu.Unlock()
+				res[ns][key] = v
+				errs = append(errs, err)
+			}()
+		}
+	}
+	wg.Wait()
+	return res, errors.Join(errs...)
+}
+
+func TestBatchingCoalescesLookups(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: 100 * time.Millisecond, MaxKeys: 1000})
+
+	res, err := getStates(qs, map[driver.Namespace][]driver.PKey{
+		"ns1": {"key1", "key2", "key2", "missing1"},
+		"ns2": {"key1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, client.GetRowsCallCount())
+	_, q, _ := client.GetRowsArgsForCall(0)
+	require.Len(t, q.GetNamespaces(), 2)
+
+	require.Equal(t, []byte("value of key1"), res["ns1"]["key1"].Raw)
+	require.Equal(t, []byte("value of key2"), res["ns1"]["key2"].Raw)
+	require.Nil(t, res["ns1"]["missing1"])
+	require.Equal(t, []byte("value of key1"), res["ns2"]["key1"].Raw)
+}
+
+func TestBatchingMaxKeys(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: time.Minute, MaxKeys: 2})
+
+	keys := make([]driver.PKey, 6)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	res, err := getStates(qs, map[driver.Namespace][]driver.PKey{"ns1": keys})
+	require.NoError(t, err)
+	require.Equal(t, 3, client.GetRowsCallCount())
+	for _, key := range keys {
+		require.Equal(t, []byte("value of "+key), res["ns1"][key].Raw)
+	}
+}
+
+func TestBatchingMaxWait(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: time.Millisecond, MaxKeys: 1000})
+
+	for i := 0; i < 3; i++ {
+		v, err := qs.GetState("ns1", "key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value of key1"), v.Raw)
+	}
+	require.Equal(t, 3, client.GetRowsCallCount())
+}
+
+func TestBatchingError(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: 50 * time.Millisecond, MaxKeys: 1000})
+	client.GetRowsReturns(nil, errUnavailable)
+	client.GetRowsStub = nil
+
+	_, err := getStates(qs, map[driver.Namespace][]driver.PKey{"ns1": {"key1", "key2", "key3"}})
+	require.ErrorIs(t, err, errUnavailable)
+	require.Equal(t, 1, client.GetRowsCallCount())
+}
+
+func TestBatchingCallerCancellation(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: 100 * time.Millisecond, MaxKeys: 1000})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := qs.GetStateContext(ctx, "ns1", "key1")
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the batch is still sent for the other callers
+	v, err := qs.GetStateContext(context.Background(), "ns1", "key2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value of key2"), v.Raw)
+	require.Equal(t, 1, client.GetRowsCallCount())
+	_, q, _ := client.GetRowsArgsForCall(0)
+	require.Len(t, q.GetNamespaces()[0].GetKeys(), 2)
+}
+
+func TestBatchingCallerSpanAndDeadline(t *testing.T) {
+	qs, client := setupBatchTest(queryservice.BatchingConfig{MaxWait: time.Minute, MaxKeys: 3})
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	first, cancelFirst := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), spanCtx), time.Second)
+	defer cancelFirst()
+	second, cancelSecond := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelSecond()
+	earliest, _ := first.Deadline()
+	client.GetRowsStub = func(rpcCtx context.Context, q *protoqueryservice.Query, opts ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		require.Equal(t, spanCtx, trace.SpanContextFromContext(rpcCtx))
+		deadline, ok := rpcCtx.Deadline()
+		require.True(t, ok)
+		require.Equal(t, earliest, deadline)
+		return echoRows(rpcCtx, q, opts...)
+	}
+
+	var wg sync.WaitGroup
+	for i, ctx := range []context.Context{first, second, context.Background()} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("key%d", i)
+			v, err := qs.GetStateContext(ctx, "ns1", key)
+			require.NoError(t, err)
+			require.Equal(t, []byte("value of "+key), v.Raw)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, client.GetRowsCallCount())
+}
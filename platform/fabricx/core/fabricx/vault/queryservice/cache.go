@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/fabricutils"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics"
+	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+)
+
+// NewStateCache returns a cache of the last size states read from the committer, including the missing ones.
+// OnBlock must receive the committed blocks of the channel, so that the states they write are evicted.
+func NewStateCache(size int, marshaller protoblocktx.Marshaller, metrics *Metrics, channel string) *stateCache {
+	return &stateCache{
+		size:       size,
+		marshaller: marshaller,
+		entries:    make(map[stateKey]*list.Element, size),
+		lru:        list.New(),
+		hits:       metrics.CacheHits.With(channelLabel, channel),
+		misses:     metrics.CacheMisses.With(channelLabel, channel),
+	}
+}
+
+type stateCache struct {
+	size       int
+	marshaller protoblocktx.Marshaller
+	hits       metrics.Counter
+	misses     metrics.Counter
+
+	mu      sync.Mutex
+	entries map[stateKey]*list.Element
+	// lru holds the entries, the most recently used first
+	lru *list.List
+	// gen counts the blocks processed, so that a state read before a block is not cached after it
+	gen uint64
+}
+
+type stateKey struct {
+	ns  driver.Namespace
+	key driver.PKey
+}
+
+type cacheEntry struct {
+	key stateKey
+	// value is nil for the keys missing in the committer
+	value *driver.VaultValue
+}
+
+// get returns the cached state of the key, if any.
+func (c *stateCache) get(ns driver.Namespace, key driver.PKey) (*driver.VaultValue, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[stateKey{ns, key}]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry).value, true
+}
+
+// generation returns the number of blocks processed so far, to be passed to put.
+func (c *stateCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// put caches the state read from the committer, unless a block was processed since the generation gen,
+// in which case the state might be older than the block.
+func (c *stateCache) put(gen uint64, ns driver.Namespace, key driver.PKey, value *driver.VaultValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		return
+	}
+	k := stateKey{ns, key}
+	if e, ok := c.entries[k]; ok {
+		e.Value.(*cacheEntry).value = value
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[k] = c.lru.PushFront(&cacheEntry{key: k, value: value})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// OnBlock evicts the states written by the valid transactions of the block.
+// The whole cache is cleared if a transaction cannot be read.
+func (c *stateCache) OnBlock(_ context.Context, block *common.Block) (bool, error) {
+	written, err := c.writtenKeys(block)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if err != nil {
+		logger.Warnf("cannot read the writes of block [%d], clear the state cache: %v", block.GetHeader().GetNumber(), err)
+		c.entries = make(map[stateKey]*list.Element, c.size)
+		c.lru.Init()
+		return false, nil
+	}
+	for _, k := range written {
+		if e, ok := c.entries[k]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, k)
+		}
+	}
+	return false, nil
+}
+
+func (c *stateCache) writtenKeys(block *common.Block) ([]stateKey, error) {
+	var written []stateKey
+	filter := block.GetMetadata().GetMetadata()[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	for i, raw := range block.GetData().GetData() {
+		if i < len(filter) && !c.marshaller.IsStatusValid(filter[i]) {
+			continue
+		}
+		_, payload, chdr, err := fabricutils.UnmarshalTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		if chdr.GetType() != int32(common.HeaderType_MESSAGE) {
+			// configuration transactions do not write states
+			continue
+		}
+		tx, err := c.marshaller.UnmarshalTx(payload.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range tx.GetNamespaces() {
+			for _, w := range ns.GetReadWrites() {
+				written = append(written, stateKey{ns.GetNsId(), driver.PKey(w.GetKey())})
+			}
+			for _, w := range ns.GetBlindWrites() {
+				written = append(written, stateKey{ns.GetNsId(), driver.PKey(w.GetKey())})
+			}
+		}
+	}
+	return written, nil
+}
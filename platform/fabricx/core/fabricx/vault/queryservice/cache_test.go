@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics/disabled"
+	"github.com/hyperledger/fabric-protos-go/common"
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
+	v2 "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/ledger"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/hyperledger/fabric/protoutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type txWrites struct {
+	status     v2.Status
+	ns         driver.Namespace
+	readWrites []driver.PKey
+	blind      []driver.PKey
+}
+
+func newBlock(t *testing.T, number uint64, txs ...txWrites) *common.Block {
+	t.Helper()
+	marshaller := v2.NewMarshallerAdapter()
+	block := protoutil.NewBlock(number, nil)
+	filter := make([]byte, len(txs))
+	for i, w := range txs {
+		var readWrites []api.ReadWrite
+		for _, k := range w.readWrites {
+			readWrites = append(readWrites, api.NewReadWrite([]byte(k), nil, []byte("new")))
+		}
+		var blind []api.Write
+		for _, k := range w.blind {
+			blind = append(blind, api.NewWrite([]byte(k), []byte("new")))
+		}
+		ns := api.NewTxNamespace(w.ns, nil, nil, readWrites, blind)
+		raw, err := marshaller.MarshalTx(api.NewTx("tx", []api.TxNamespace{ns}, nil))
+		require.NoError(t, err)
+
+		chdr := protoutil.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "channel", 0)
+		payload := &common.Payload{Header: protoutil.MakePayloadHeader(chdr, &common.SignatureHeader{}), Data: raw}
+		block.Data.Data = append(block.Data.Data, protoutil.MarshalOrPanic(&common.Envelope{Payload: protoutil.MarshalOrPanic(payload)}))
+		filter[i] = byte(w.status)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = filter
+	return block
+}
+
+type marshallerProvider struct{}
+
+func (marshallerProvider) Get(string, string) (api.Marshaller, error) {
+	return v2.NewMarshallerAdapter(), nil
+}
+
+// setupCacheTest returns a query service caching size states, and the function delivering a block to the channel
+func setupCacheTest(t *testing.T, size int) (queryservice.QueryService, *protoqueryservicefakes.FakeQueryServiceClient, func(*common.Block)) {
+	t.Helper()
+	client := &protoqueryservicefakes.FakeQueryServiceClient{}
+	client.GetRowsStub = echoRows
+	configs := &configProvider{config: map[string]any{"queryService.cache.enabled": true, "queryService.cache.size": size}}
+	dispatchers := ledger.NewBlockDispatcherProvider()
+	p := queryservice.NewProvider(clientProvider{client: protoqueryservice.NewServiceAdapter(client)}, configs, &disabled.Provider{}, marshallerProvider{}, dispatchers)
+	qs, err := p.Get("network", "channel")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.(*queryservice.RemoteQueryServiceProvider).Stop() })
+
+	dispatcher, err := dispatchers.GetBlockDispatcher("network", "channel")
+	require.NoError(t, err)
+	return qs, client, func(block *common.Block) {
+		_, err := dispatcher.OnBlock(context.Background(), block)
+		require.NoError(t, err)
+	}
+}
+
+func TestCacheHits(t *testing.T) {
+	qs, client, _ := setupCacheTest(t, 10)
+
+	for i := 0; i < 3; i++ {
+		v, err := qs.GetState("ns1", "key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value of key1"), v.Raw)
+		v, err = qs.GetState("ns1", "missing1")
+		require.NoError(t, err)
+		require.Nil(t, v)
+	}
+	require.Equal(t, 2, client.GetRowsCallCount())
+
+	// only the missing states are queried
+	res, err := qs.GetStates(map[driver.Namespace][]driver.PKey{"ns1": {"key1", "key2", "missing1"}})
+	require.NoError(t, err)
+	require.Equal(t, map[driver.Namespace]map[driver.PKey]driver.VaultValue{"ns1": {
+		"key1": {Raw: []byte("value of key1"), Version: row("key1", 1).Version},
+		"key2": {Raw: []byte("value of key2"), Version: row("key2", 1).Version},
+	}}, res)
+	require.Equal(t, 3, client.GetRowsCallCount())
+	_, q, _ := client.GetRowsArgsForCall(2)
+	require.Equal(t, [][]byte{[]byte("key2")}, q.GetNamespaces()[0].GetKeys())
+}
+
+func TestCacheEviction(t *testing.T) {
+	qs, client, _ := setupCacheTest(t, 2)
+
+	for _, key := range []driver.PKey{"key1", "key2", "key1", "key3", "key1", "key2"} {
+		_, err := qs.GetState("ns1", key)
+		require.NoError(t, err)
+	}
+	// key1 is kept as the most recently used, key2 is evicted by key3
+	require.Equal(t, 4, client.GetRowsCallCount())
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	qs, client, onBlock := setupCacheTest(t, 10)
+	keys := []driver.PKey{"key1", "key2", "key3", "key4", "key5"}
+	for _, key := range keys {
+		_, err := qs.GetState("ns1", key)
+		require.NoError(t, err)
+	}
+	require.Equal(t, len(keys), client.GetRowsCallCount())
+
+	onBlock(newBlock(t, 1,
+		txWrites{status: v2.Status_COMMITTED, ns: "ns1", readWrites: []driver.PKey{"key1"}, blind: []driver.PKey{"key2"}},
+		txWrites{status: v2.Status_ABORTED_MVCC_CONFLICT, ns: "ns1", readWrites: []driver.PKey{"key3"}},
+		txWrites{status: v2.Status_COMMITTED, ns: "ns2", readWrites: []driver.PKey{"key4"}},
+	))
+
+	// the states written by the committed transactions are read again, the others are still cached
+	for _, key := range keys {
+		_, err := qs.GetState("ns1", key)
+		require.NoError(t, err)
+	}
+	require.Equal(t, len(keys)+2, client.GetRowsCallCount())
+	var queried []string
+	for i := len(keys); i < len(keys)+2; i++ {
+		_, q, _ := client.GetRowsArgsForCall(i)
+		queried = append(queried, string(q.GetNamespaces()[0].GetKeys()[0]))
+	}
+	require.Equal(t, []string{"key1", "key2"}, queried)
+}
+
+func TestCacheSkipsStatesReadBeforeBlock(t *testing.T) {
+	qs, client, onBlock := setupCacheTest(t, 10)
+
+	// a block is processed while the state is being read
+	client.GetRowsStub = func(ctx context.Context, q *protoqueryservice.Query, opts ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		onBlock(newBlock(t, 1, txWrites{status: v2.Status_COMMITTED, ns: "ns1", readWrites: []driver.PKey{"key1"}}))
+		return echoRows(ctx, q, opts...)
+	}
+	_, err := qs.GetState("ns1", "key1")
+	require.NoError(t, err)
+
+	client.GetRowsStub = echoRows
+	_, err = qs.GetState("ns1", "key1")
+	require.NoError(t, err)
+	_, err = qs.GetState("ns1", "key1")
+	require.NoError(t, err)
+	require.Equal(t, 2, client.GetRowsCallCount())
+}
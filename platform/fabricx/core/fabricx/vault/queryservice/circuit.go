@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned instead of calling a replica whose circuit is open.
+// It has the Unavailable code, so that the calls fail over and are retried as for a replica down.
+var ErrCircuitOpen = status.Error(codes.Unavailable, "query service circuit open")
+
+// CircuitState is the state of the circuit breaker of a replica.
+type CircuitState int
+
+const (
+	// CircuitClosed lets the calls through.
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen lets a single call through, probing whether the replica is back.
+	CircuitHalfOpen
+	// CircuitOpen rejects the calls.
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuit is the circuit breaker of a replica. It is guarded by the mutex of the replica.
+type circuit struct {
+	config CircuitBreakerConfig
+
+	state CircuitState
+	// failures is the number of consecutive calls that found the replica unavailable
+	failures int
+	// openUntil is the time after which the open circuit lets a probe through
+	openUntil time.Time
+	// probing tells whether the probe of the half-open circuit is in flight
+	probing bool
+}
+
+// current returns the state of the circuit at the time.
+func (c *circuit) current(now time.Time) CircuitState {
+	if c.state == CircuitOpen && !now.Before(c.openUntil) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow tells whether a call can be sent to the replica, and then accounts for it.
+// probe tells whether the call is the probe of the half-open circuit, to be passed to record.
+func (c *circuit) allow(now time.Time) (probe bool, ok bool) {
+	if !c.config.Enabled {
+		return false, true
+	}
+	switch c.current(now) {
+	case CircuitClosed:
+		return false, true
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, false
+		}
+		c.state, c.probing = CircuitHalfOpen, true
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// record updates the circuit with the outcome of an allowed call, and returns whether the circuit opened.
+// The calls cancelled or timed out by the caller tell nothing about the replica.
+// Once the circuit left the closed state, only the outcome of its probe counts: the calls allowed
+// before it opened may complete late, and must neither close it nor end the probe.
+func (c *circuit) record(now time.Time, err error, probe bool) bool {
+	if !c.config.Enabled {
+		return false
+	}
+	if probe {
+		c.probing = false
+	} else if c.state != CircuitClosed {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable:
+		c.failures++
+		if probe || c.failures >= c.config.FailureThreshold {
+			c.state, c.openUntil = CircuitOpen, now.Add(c.config.OpenTimeout)
+			return true
+		}
+	case codes.Canceled, codes.DeadlineExceeded:
+	default:
+		c.state, c.failures = CircuitClosed, 0
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const openTimeout = 50 * time.Millisecond
+
+func circuitBreakerConfig() queryservice.LoadBalancingConfig {
+	return queryservice.LoadBalancingConfig{
+		CircuitBreaker: queryservice.CircuitBreakerConfig{Enabled: true, FailureThreshold: 2, OpenTimeout: openTimeout},
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	b, fakes := setupBalancerConfig(t, circuitBreakerConfig(), 1)
+	fakes[0].GetRowsReturns(nil, errUnavailable)
+
+	// the circuit opens after two failures, and the replica is no longer called
+	require.ErrorIs(t, getRows(t, b), errUnavailable)
+	require.Equal(t, queryservice.CircuitClosed, b.Health()[0].Circuit)
+	require.ErrorIs(t, getRows(t, b), errUnavailable)
+	require.Equal(t, queryservice.CircuitOpen, b.Health()[0].Circuit)
+	require.False(t, b.Health()[0].Healthy)
+	require.ErrorIs(t, getRows(t, b), queryservice.ErrCircuitOpen)
+	require.Equal(t, 2, fakes[0].GetRowsCallCount())
+
+	// a probe closes the circuit once the replica is back
+	fakes[0].GetRowsReturns(&protoqueryservice.Rows{}, nil)
+	time.Sleep(openTimeout)
+	require.Equal(t, queryservice.CircuitHalfOpen, b.Health()[0].Circuit)
+	require.NoError(t, getRows(t, b))
+	require.Equal(t, queryservice.CircuitClosed, b.Health()[0].Circuit)
+	require.True(t, b.Health()[0].Healthy)
+}
+
+func TestCircuitBreakerProbeFails(t *testing.T) {
+	b, fakes := setupBalancerConfig(t, circuitBreakerConfig(), 1)
+	fakes[0].GetRowsReturns(nil, errUnavailable)
+	for i := 0; i < 2; i++ {
+		require.Error(t, getRows(t, b))
+	}
+
+	// a failed probe opens the circuit again at once
+	time.Sleep(openTimeout)
+	require.ErrorIs(t, getRows(t, b), errUnavailable)
+	require.Equal(t, queryservice.CircuitOpen, b.Health()[0].Circuit)
+	require.ErrorIs(t, getRows(t, b), queryservice.ErrCircuitOpen)
+	require.Equal(t, 3, fakes[0].GetRowsCallCount())
+}
+
+func TestCircuitBreakerFailover(t *testing.T) {
+	b, fakes := setupBalancerConfig(t, circuitBreakerConfig(), 2)
+	fakes[0].GetRowsReturns(nil, errUnavailable)
+
+	// the calls fail over to the second replica, and the first one is no longer tried once its circuit is open
+	for i := 0; i < 6; i++ {
+		require.NoError(t, getRows(t, b))
+	}
+	require.Equal(t, 2, fakes[0].GetRowsCallCount())
+	require.Equal(t, 6, fakes[1].GetRowsCallCount())
+}
+
+func TestCircuitBreakerIgnoresOtherErrors(t *testing.T) {
+	b, fakes := setupBalancerConfig(t, circuitBreakerConfig(), 1)
+	fakes[0].GetRowsReturns(nil, errors.New("invalid query"))
+	for i := 0; i < 3; i++ {
+		require.EqualError(t, getRows(t, b), "invalid query")
+	}
+	require.Equal(t, queryservice.CircuitClosed, b.Health()[0].Circuit)
+
+	// the calls cancelled by the caller do not count either
+	fakes[0].GetRowsReturns(nil, context.Canceled)
+	for i := 0; i < 3; i++ {
+		require.Error(t, getRows(t, b))
+	}
+	require.Equal(t, queryservice.CircuitClosed, b.Health()[0].Circuit)
+}
+
+func TestCircuitBreakerIgnoresLateOutcomes(t *testing.T) {
+	b, fakes := setupBalancerConfig(t, circuitBreakerConfig(), 1)
+	// each call waits for the outcome sent on the channel it takes from replies
+	replies := make(chan chan error, 4)
+	fakes[0].GetRowsStub = func(context.Context, *protoqueryservice.Query, ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		return &protoqueryservice.Rows{}, <-<-replies
+	}
+	reply := func(err error) chan error {
+		c := make(chan error, 1)
+		if err != nil {
+			c <- err
+		}
+		replies <- c
+		return c
+	}
+	async := func() <-chan error {
+		done := make(chan error, 1)
+		go func() { done <- getRows(t, b) }()
+		return done
+	}
+
+	// a slow call is allowed while the circuit is closed, and then two failures open it
+	slow := reply(nil)
+	slowDone := async()
+	require.Eventually(t, func() bool { return fakes[0].GetRowsCallCount() == 1 }, time.Second, time.Millisecond)
+	for i := 0; i < 2; i++ {
+		reply(errUnavailable)
+		require.ErrorIs(t, getRows(t, b), errUnavailable)
+	}
+	require.Equal(t, queryservice.CircuitOpen, b.Health()[0].Circuit)
+
+	// the probe is in flight when the slow call succeeds
+	time.Sleep(openTimeout)
+	probe := reply(nil)
+	probeDone := async()
+	require.Eventually(t, func() bool { return fakes[0].GetRowsCallCount() == 4 }, time.Second, time.Millisecond)
+	slow <- nil
+	require.NoError(t, <-slowDone)
+
+	// the late success neither closes the circuit nor lets a second probe through
+	require.Equal(t, queryservice.CircuitHalfOpen, b.Health()[0].Circuit)
+	require.ErrorIs(t, getRows(t, b), queryservice.ErrCircuitOpen)
+
+	// only the outcome of the probe counts
+	probe <- errUnavailable
+	require.ErrorIs(t, <-probeDone, errUnavailable)
+	require.Equal(t, queryservice.CircuitOpen, b.Health()[0].Circuit)
+	require.Equal(t, 4, fakes[0].GetRowsCallCount())
+}
@@ -8,21 +8,121 @@ package queryservice
 
 import (
 	"fmt"
+	"slices"
 	"time"
+
+	"google.golang.org/grpc/codes"
 )
 
-const DefaultQueryTimeout = 30 * time.Second
+const (
+	DefaultQueryTimeout   = 30 * time.Second
+	DefaultFailureBackoff = 5 * time.Second
+	DefaultBatchMaxWait   = 2 * time.Millisecond
+	DefaultBatchMaxKeys   = 100
+	DefaultCacheSize      = 10000
+
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 50 * time.Millisecond
+	DefaultRetryMaxBackoff     = time.Second
+
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenTimeout      = 30 * time.Second
+)
+
+// DefaultRetryCodes are the gRPC status codes of the transient failures
+var DefaultRetryCodes = []string{codes.Unavailable.String(), codes.ResourceExhausted.String()}
 
 type Config struct {
-	Endpoints    []Endpoint    `yaml:"endpoints,omitempty"`
-	QueryTimeout time.Duration `yaml:"queryTimeout,omitempty"`
+	Endpoints     []Endpoint          `yaml:"endpoints,omitempty"`
+	QueryTimeout  time.Duration       `yaml:"queryTimeout,omitempty"`
+	View          ViewConfig          `yaml:"view,omitempty"`
+	LoadBalancing LoadBalancingConfig `yaml:"loadBalancing,omitempty"`
+	KeepAlive     KeepAliveConfig     `yaml:"keepAlive,omitempty"`
+	Batching      BatchingConfig      `yaml:"batching,omitempty"`
+	Cache         CacheConfig         `yaml:"cache,omitempty"`
+	Retry         RetryConfig         `yaml:"retry,omitempty"`
+	// RangePageSize is the number of states the committer streams at a time for range queries.
+	// Zero lets the committer choose it.
+	RangePageSize uint32 `yaml:"rangePageSize,omitempty"`
+}
+
+// LoadBalancingConfig tells how the queries are spread over the endpoints.
+// Policy is roundRobin, the default, or leastLatency.
+// An endpoint found unavailable is skipped for FailureBackoff, unless all the endpoints are.
+type LoadBalancingConfig struct {
+	Policy         string               `yaml:"policy,omitempty"`
+	FailureBackoff time.Duration        `yaml:"failureBackoff,omitempty"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker,omitempty"`
+}
+
+// CircuitBreakerConfig tells whether the calls to an endpoint are stopped after FailureThreshold
+// consecutive calls found it unavailable. Its circuit then opens for OpenTimeout,
+// after which a single call probes whether the endpoint is back.
+// Unlike the failure backoff, an open circuit is not tried even when all the endpoints are down.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `yaml:"enabled,omitempty"`
+	FailureThreshold int           `yaml:"failureThreshold,omitempty"`
+	OpenTimeout      time.Duration `yaml:"openTimeout,omitempty"`
+}
+
+// KeepAliveConfig tells how the idle connections to the endpoints are kept alive.
+// A ping is sent after Time without activity, and the connection is closed if not acknowledged within Timeout.
+// A zero Time disables the pings; gRPC raises a Time below 10 seconds to 10 seconds.
+// Servers may close the connections pinging too often, or without calls when PermitWithoutStream is set.
+type KeepAliveConfig struct {
+	Time                time.Duration `yaml:"time,omitempty"`
+	Timeout             time.Duration `yaml:"timeout,omitempty"`
+	PermitWithoutStream bool          `yaml:"permitWithoutStream,omitempty"`
+}
+
+// BatchingConfig tells whether the concurrent lookups of single keys outside of views are coalesced into one query.
+// A batch is sent MaxWait after its first lookup, or as soon as it holds MaxKeys keys.
+type BatchingConfig struct {
+	Enabled bool          `yaml:"enabled,omitempty"`
+	MaxWait time.Duration `yaml:"maxWait,omitempty"`
+	MaxKeys int           `yaml:"maxKeys,omitempty"`
 }
 
+// CacheConfig tells whether the last Size states read outside of views are cached.
+// The cached states are evicted when the blocks delivered to the endorser write them.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	Size    int  `yaml:"size,omitempty"`
+}
+
+// RetryConfig tells whether the queries failing with one of Codes are tried again, up to MaxAttempts calls in total.
+// The wait before a retry starts at InitialBackoff and doubles up to MaxBackoff, with jitter.
+// All the attempts of a query run within its query timeout.
+// Only the state reads (GetRows) are retried: views, policies and range queries fail at the first error.
+type RetryConfig struct {
+	Enabled        bool          `yaml:"enabled,omitempty"`
+	MaxAttempts    int           `yaml:"maxAttempts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initialBackoff,omitempty"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff,omitempty"`
+	Codes          []string      `yaml:"codes,omitempty"`
+}
+
+// ViewConfig tells whether the queries of a transaction run against a single view of the committer state.
+// IsolationLevel is one of serializable, repeatableRead, readCommitted and readUncommitted, and applies
+// to the transactions that do not choose one. A zero Timeout lets the committer pick the maximal one.
+type ViewConfig struct {
+	Enabled        bool          `yaml:"enabled,omitempty"`
+	IsolationLevel string        `yaml:"isolationLevel,omitempty"`
+	NonDeferrable  bool          `yaml:"nonDeferrable,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Endpoint is a query service endpoint of the committer.
+// The server certificate is trusted if issued by any of TLSRootCertFile and TLSRootCertFiles.
+// TLSClientCertFile and TLSClientKeyFile are the certificate and key presented to the server for mutual TLS.
 type Endpoint struct {
 	Address               string        `yaml:"address,omitempty"`
 	ConnectionTimeout     time.Duration `yaml:"connectionTimeout,omitempty"`
 	TLSEnabled            bool          `yaml:"tlsEnabled,omitempty"`
 	TLSRootCertFile       string        `yaml:"tlsRootCertFile,omitempty"`
+	TLSRootCertFiles      []string      `yaml:"tlsRootCertFiles,omitempty"`
+	TLSClientCertFile     string        `yaml:"tlsClientCertFile,omitempty"`
+	TLSClientKeyFile      string        `yaml:"tlsClientKeyFile,omitempty"`
 	TLSServerNameOverride string        `yaml:"tlsServerNameOverride,omitempty"`
 }
 
@@ -33,12 +133,49 @@ type ConfigService interface {
 func NewConfig(configService ConfigService) (*Config, error) {
 	config := &Config{
 		QueryTimeout: DefaultQueryTimeout,
+		LoadBalancing: LoadBalancingConfig{
+			FailureBackoff: DefaultFailureBackoff,
+			CircuitBreaker: CircuitBreakerConfig{FailureThreshold: DefaultCircuitFailureThreshold, OpenTimeout: DefaultCircuitOpenTimeout},
+		},
+		Batching: BatchingConfig{MaxWait: DefaultBatchMaxWait, MaxKeys: DefaultBatchMaxKeys},
+		Cache:    CacheConfig{Size: DefaultCacheSize},
+		Retry: RetryConfig{
+			MaxAttempts:    DefaultRetryMaxAttempts,
+			InitialBackoff: DefaultRetryInitialBackoff,
+			MaxBackoff:     DefaultRetryMaxBackoff,
+			Codes:          slices.Clone(DefaultRetryCodes),
+		},
 	}
 
 	err := configService.UnmarshalKey("queryService", &config)
 	if err != nil {
 		return config, fmt.Errorf("cannot get query service config: %w", err)
 	}
+	if _, ok := isolationLevels[config.View.IsolationLevel]; !ok {
+		return config, fmt.Errorf("invalid view isolation level [%s]", config.View.IsolationLevel)
+	}
+	switch config.LoadBalancing.Policy {
+	case "", RoundRobin, LeastLatency:
+	default:
+		return config, fmt.Errorf("invalid load balancing policy [%s]", config.LoadBalancing.Policy)
+	}
+	if config.Batching.Enabled && (config.Batching.MaxWait <= 0 || config.Batching.MaxKeys <= 0) {
+		return config, fmt.Errorf("invalid batching config: maxWait and maxKeys must be positive")
+	}
+	if config.Cache.Enabled && config.Cache.Size <= 0 {
+		return config, fmt.Errorf("invalid cache size [%d]", config.Cache.Size)
+	}
+	if retry := config.Retry; retry.Enabled {
+		if retry.MaxAttempts <= 0 || retry.InitialBackoff <= 0 || retry.MaxBackoff < retry.InitialBackoff {
+			return config, fmt.Errorf("invalid retry config: maxAttempts and initialBackoff must be positive, and maxBackoff at least initialBackoff")
+		}
+		if _, err := parseCodes(retry.Codes); err != nil {
+			return config, fmt.Errorf("invalid retry config: %w", err)
+		}
+	}
+	if cb := config.LoadBalancing.CircuitBreaker; cb.Enabled && (cb.FailureThreshold <= 0 || cb.OpenTimeout <= 0) {
+		return config, fmt.Errorf("invalid circuit breaker config: failureThreshold and openTimeout must be positive")
+	}
 
 	return config, nil
 }
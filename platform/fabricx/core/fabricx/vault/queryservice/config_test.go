@@ -31,6 +31,40 @@ func (c configService) UnmarshalKey(key string, rawVal interface{}) error {
 	return c.V.UnmarshalKey(key, rawVal)
 }
 
+func TestInvalidViewConfig(t *testing.T) {
+	cs := newConfigService(map[string]any{"queryService.view.isolationLevel": "snapshot"})
+	_, err := queryservice.NewConfig(cs)
+	require.Error(t, err)
+}
+
+func TestInvalidLoadBalancingConfig(t *testing.T) {
+	cs := newConfigService(map[string]any{"queryService.loadBalancing.policy": "random"})
+	_, err := queryservice.NewConfig(cs)
+	require.Error(t, err)
+}
+
+func TestInvalidBatchingConfig(t *testing.T) {
+	cs := newConfigService(map[string]any{"queryService.batching.enabled": true, "queryService.batching.maxKeys": -1})
+	_, err := queryservice.NewConfig(cs)
+	require.Error(t, err)
+}
+
+func TestInvalidRetryConfig(t *testing.T) {
+	cs := newConfigService(map[string]any{"queryService.retry.enabled": true, "queryService.retry.codes": []string{"Unavailable", "Flaky"}})
+	_, err := queryservice.NewConfig(cs)
+	require.ErrorContains(t, err, "Flaky")
+
+	cs = newConfigService(map[string]any{"queryService.retry.enabled": true, "queryService.retry.maxBackoff": time.Millisecond})
+	_, err = queryservice.NewConfig(cs)
+	require.Error(t, err)
+}
+
+func TestInvalidCircuitBreakerConfig(t *testing.T) {
+	cs := newConfigService(map[string]any{"queryService.loadBalancing.circuitBreaker.enabled": true, "queryService.loadBalancing.circuitBreaker.failureThreshold": 0})
+	_, err := queryservice.NewConfig(cs)
+	require.Error(t, err)
+}
+
 func TestConfig(t *testing.T) {
 	table := []struct {
 		name   string
@@ -44,6 +78,14 @@ func TestConfig(t *testing.T) {
 				t.Helper()
 				require.Equal(t, queryservice.DefaultQueryTimeout, c.QueryTimeout)
 				require.Empty(t, c.Endpoints)
+				require.Empty(t, c.LoadBalancing.Policy)
+				require.Equal(t, queryservice.DefaultFailureBackoff, c.LoadBalancing.FailureBackoff)
+				require.Equal(t, queryservice.BatchingConfig{MaxWait: queryservice.DefaultBatchMaxWait, MaxKeys: queryservice.DefaultBatchMaxKeys}, c.Batching)
+				require.False(t, c.Retry.Enabled)
+				require.Equal(t, queryservice.DefaultRetryMaxAttempts, c.Retry.MaxAttempts)
+				require.Equal(t, queryservice.DefaultRetryCodes, c.Retry.Codes)
+				require.False(t, c.LoadBalancing.CircuitBreaker.Enabled)
+				require.Equal(t, queryservice.DefaultCircuitFailureThreshold, c.LoadBalancing.CircuitBreaker.FailureThreshold)
 			},
 		},
 		{
@@ -78,6 +120,45 @@ func TestConfig(t *testing.T) {
 				require.Equal(t, "/tmp/rootcert", c.Endpoints[0].TLSRootCertFile)
 			},
 		},
+		{
+			name: "view",
+			cfg: map[string]any{
+				"queryService.view.enabled":        true,
+				"queryService.view.isolationLevel": "readCommitted",
+				"queryService.view.timeout":        2 * time.Second,
+			},
+			checks: func(t *testing.T, c *queryservice.Config) {
+				t.Helper()
+				require.True(t, c.View.Enabled)
+				require.Equal(t, "readCommitted", c.View.IsolationLevel)
+				require.Equal(t, 2*time.Second, c.View.Timeout)
+				require.False(t, c.View.NonDeferrable)
+			},
+		},
+		{
+			name: "load balancing",
+			cfg: map[string]any{
+				"queryService.loadBalancing.policy":         "leastLatency",
+				"queryService.loadBalancing.failureBackoff": time.Second,
+			},
+			checks: func(t *testing.T, c *queryservice.Config) {
+				t.Helper()
+				require.Equal(t, queryservice.LeastLatency, c.LoadBalancing.Policy)
+				require.Equal(t, time.Second, c.LoadBalancing.FailureBackoff)
+			},
+		},
+		{
+			name: "keepalive",
+			cfg: map[string]any{
+				"queryService.keepAlive.time":                30 * time.Second,
+				"queryService.keepAlive.timeout":             5 * time.Second,
+				"queryService.keepAlive.permitWithoutStream": true,
+			},
+			checks: func(t *testing.T, c *queryservice.Config) {
+				t.Helper()
+				require.Equal(t, queryservice.KeepAliveConfig{Time: 30 * time.Second, Timeout: 5 * time.Second, PermitWithoutStream: true}, c.KeepAlive)
+			},
+		},
 	}
 
 	for _, tc := range table {
@@ -7,57 +7,75 @@ SPDX-License-Identifier: Apache-2.0
 package queryservice
 
 import (
-	"errors"
 	"fmt"
-	"os"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
-var ErrInvalidAddress = fmt.Errorf("empty address")
+var (
+	ErrInvalidAddress = fmt.Errorf("empty address")
+	ErrNoEndpoints    = fmt.Errorf("no query service endpoint")
+)
 
-func GrpcClient(c *Config) (*grpc.ClientConn, error) {
-	// no endpoints in config
-	if len(c.Endpoints) != 1 {
-		return nil, fmt.Errorf("we need a single query service endpoint")
+// GrpcClients returns a connection to each endpoint of the configuration, in the same order.
+func GrpcClients(c *Config) ([]*grpc.ClientConn, error) {
+	if len(c.Endpoints) == 0 {
+		return nil, ErrNoEndpoints
 	}
 
-	// currently we only support connections to a single query service
-	endpoint := c.Endpoints[0]
+	conns := make([]*grpc.ClientConn, 0, len(c.Endpoints))
+	for _, endpoint := range c.Endpoints {
+		conn, err := GrpcClient(endpoint, WithKeepAlive(c.KeepAlive))
+		if err != nil {
+			for _, conn := range conns {
+				_ = conn.Close()
+			}
+			return nil, fmt.Errorf("cannot connect to query service [%s]: %w", endpoint.Address, err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
 
+// GrpcClient returns a connection to the endpoint, with the additional options.
+func GrpcClient(endpoint Endpoint, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	// check endpoint address
 	if len(endpoint.Address) == 0 {
 		return nil, ErrInvalidAddress
 	}
 
+	tlsOpt, err := WithTLS(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
 	var opts []grpc.DialOption
 	opts = append(opts, WithConnectionTime(endpoint.ConnectionTimeout))
-	opts = append(opts, WithTLS(endpoint))
+	opts = append(opts, tlsOpt)
+	// the trace span of the calls is propagated to the committer
+	opts = append(opts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	opts = append(opts, extraOpts...)
 
 	return grpc.NewClient(endpoint.Address, opts...)
 }
 
-func WithTLS(endpoint Endpoint) grpc.DialOption {
+// WithTLS returns the transport credentials of the endpoint, see TLSConfig.
+func WithTLS(endpoint Endpoint) (grpc.DialOption, error) {
 	if !endpoint.TLSEnabled {
-		return grpc.WithTransportCredentials(insecure.NewCredentials())
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
 	}
 
-	if _, err := os.Stat(endpoint.TLSRootCertFile); errors.Is(err, os.ErrNotExist) {
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	creds, err := credentials.NewClientTLSFromFile(endpoint.TLSRootCertFile, endpoint.TLSServerNameOverride)
+	config, err := TLSConfig(endpoint)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-
-	return grpc.WithTransportCredentials(creds)
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
 }
 
 func WithConnectionTime(timeout time.Duration) grpc.DialOption {
@@ -69,3 +87,15 @@ func WithConnectionTime(timeout time.Duration) grpc.DialOption {
 		MinConnectTimeout: timeout,
 	})
 }
+
+// WithKeepAlive returns the keepalive parameters of the configuration, or no option if the pings are disabled.
+func WithKeepAlive(c KeepAliveConfig) grpc.DialOption {
+	if c.Time <= 0 {
+		return grpc.EmptyDialOption{}
+	}
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                c.Time,
+		Timeout:             c.Timeout,
+		PermitWithoutStream: c.PermitWithoutStream,
+	})
+}
@@ -62,25 +62,27 @@ func createTestCaCert(t *testing.T) string {
 
 func TestGRPC(t *testing.T) {
 	tmpCertPath := createTestCaCert(t)
+	ca := newTestCA(t, "ca")
+	clientCertPath, clientKeyPath := ca.issueFiles(t, "client")
 
 	table := []struct {
 		name   string
 		cfg    map[string]any
-		checks func(t *testing.T, client *grpc.ClientConn, err error)
+		checks func(t *testing.T, conns []*grpc.ClientConn, err error)
 	}{
 		{
 			name: "no endpoints in config",
 			cfg: map[string]any{
 				"queryService.queryTimeout": 10 * time.Second,
 			},
-			checks: func(t *testing.T, client *grpc.ClientConn, err error) {
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
 				t.Helper()
-				require.Nil(t, client)
-				require.Error(t, err)
+				require.Nil(t, conns)
+				require.ErrorIs(t, err, queryservice.ErrNoEndpoints)
 			},
 		},
 		{
-			name: "too many endpoints",
+			name: "many endpoints",
 			cfg: map[string]any{
 				"queryService.queryTimeout": 10 * time.Second,
 				"queryService.Endpoints": []any{
@@ -92,10 +94,12 @@ func TestGRPC(t *testing.T) {
 					},
 				},
 			},
-			checks: func(t *testing.T, client *grpc.ClientConn, err error) {
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
 				t.Helper()
-				require.Nil(t, client)
-				require.Error(t, err)
+				require.NoError(t, err)
+				require.Len(t, conns, 2)
+				require.Equal(t, "localhost:9988", conns[0].Target())
+				require.Equal(t, "localhost:9999", conns[1].Target())
 			},
 		},
 		{
@@ -108,9 +112,9 @@ func TestGRPC(t *testing.T) {
 					},
 				},
 			},
-			checks: func(t *testing.T, client *grpc.ClientConn, err error) {
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
 				t.Helper()
-				require.Nil(t, client)
+				require.Nil(t, conns)
 				require.ErrorIs(t, err, queryservice.ErrInvalidAddress)
 			},
 		},
@@ -125,10 +129,10 @@ func TestGRPC(t *testing.T) {
 					},
 				},
 			},
-			checks: func(t *testing.T, client *grpc.ClientConn, err error) {
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
 				t.Helper()
-				require.NotNil(t, client)
 				require.NoError(t, err)
+				require.Len(t, conns, 1)
 			},
 		},
 		{
@@ -143,10 +147,67 @@ func TestGRPC(t *testing.T) {
 					},
 				},
 			},
-			checks: func(t *testing.T, client *grpc.ClientConn, err error) {
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
+				t.Helper()
+				require.NoError(t, err)
+				require.Len(t, conns, 1)
+			},
+		},
+		{
+			name: "with mutual TLS and several root certificates",
+			cfg: map[string]any{
+				"queryService.queryTimeout": 10 * time.Second,
+				"queryService.Endpoints": []any{
+					map[string]any{
+						"address":           "localhost:8899",
+						"tlsEnabled":        true,
+						"tlsRootCertFiles":  []string{tmpCertPath, tmpCertPath},
+						"tlsClientCertFile": clientCertPath,
+						"tlsClientKeyFile":  clientKeyPath,
+					},
+				},
+			},
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
 				t.Helper()
-				require.NotNil(t, client)
 				require.NoError(t, err)
+				require.Len(t, conns, 1)
+			},
+		},
+		{
+			name: "with missing root certificate",
+			cfg: map[string]any{
+				"queryService.queryTimeout": 10 * time.Second,
+				"queryService.Endpoints": []any{
+					map[string]any{
+						"address":         "localhost:8899",
+						"tlsEnabled":      true,
+						"tlsRootCertFile": path.Join(t.TempDir(), "missing.crt"),
+					},
+				},
+			},
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
+				t.Helper()
+				require.Nil(t, conns)
+				require.ErrorIs(t, err, os.ErrNotExist)
+			},
+		},
+		{
+			name: "with client certificate but no key",
+			cfg: map[string]any{
+				"queryService.queryTimeout": 10 * time.Second,
+				"queryService.Endpoints": []any{
+					map[string]any{
+						"address":           "localhost:8899",
+						"tlsEnabled":        true,
+						"tlsRootCertFile":   tmpCertPath,
+						"tlsClientCertFile": clientCertPath,
+					},
+				},
+			},
+			checks: func(t *testing.T, conns []*grpc.ClientConn, err error) {
+				t.Helper()
+				require.Nil(t, conns)
+				require.ErrorContains(t, err, "client certificate and key must be set together")
 			},
 		},
 	}
@@ -157,8 +218,8 @@ func TestGRPC(t *testing.T) {
 			cs := newConfigService(tc.cfg)
 			c, err := queryservice.NewConfig(cs)
 			require.NoError(t, err)
-			client, err := queryservice.GrpcClient(c)
-			tc.checks(t, client, err)
+			conns, err := queryservice.GrpcClients(c)
+			tc.checks(t, conns, err)
 		})
 	}
 }
@@ -0,0 +1,109 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics"
+)
+
+const (
+	endpointLabel = "endpoint"
+	outcomeLabel  = "outcome"
+	channelLabel  = "channel"
+
+	successOutcome     = "success"
+	unavailableOutcome = "unavailable"
+	failedOutcome      = "failed"
+)
+
+type Metrics struct {
+	EndpointHealthy metrics.Gauge
+	CircuitState    metrics.Gauge
+	Calls           metrics.Counter
+	CallDuration    metrics.Histogram
+	CacheHits       metrics.Counter
+	CacheMisses     metrics.Counter
+}
+
+func NewMetrics(p metrics.Provider) *Metrics {
+	return &Metrics{
+		EndpointHealthy: p.NewGauge(metrics.GaugeOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "endpoint_healthy",
+			Help:         "Whether the query service endpoint is healthy (1) or skipped by the load balancer (0)",
+			LabelNames:   []string{endpointLabel},
+			StatsdFormat: "%{#fqname}.%{" + endpointLabel + "}",
+		}),
+		CircuitState: p.NewGauge(metrics.GaugeOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "circuit_state",
+			Help:         "The state of the circuit breaker of the query service endpoint: closed (0), half-open (1) or open (2)",
+			LabelNames:   []string{endpointLabel},
+			StatsdFormat: "%{#fqname}.%{" + endpointLabel + "}",
+		}),
+		Calls: p.NewCounter(metrics.CounterOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "calls",
+			Help:         "The number of calls to the query service endpoint, by outcome",
+			LabelNames:   []string{endpointLabel, outcomeLabel},
+			StatsdFormat: "%{#fqname}.%{" + endpointLabel + "}.%{" + outcomeLabel + "}",
+		}),
+		CallDuration: p.NewHistogram(metrics.HistogramOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "call_duration",
+			Help:         "The duration of the calls to the query service endpoint, in seconds",
+			LabelNames:   []string{endpointLabel},
+			StatsdFormat: "%{#fqname}.%{" + endpointLabel + "}",
+		}),
+		CacheHits: p.NewCounter(metrics.CounterOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "cache_hits",
+			Help:         "The number of lookups answered by the state cache of the channel",
+			LabelNames:   []string{channelLabel},
+			StatsdFormat: "%{#fqname}.%{" + channelLabel + "}",
+		}),
+		CacheMisses: p.NewCounter(metrics.CounterOpts{
+			Namespace:    "fabricx",
+			Subsystem:    "query_service",
+			Name:         "cache_misses",
+			Help:         "The number of lookups missing in the state cache of the channel, and sent to the committer",
+			LabelNames:   []string{channelLabel},
+			StatsdFormat: "%{#fqname}.%{" + channelLabel + "}",
+		}),
+	}
+}
+
+func (m *Metrics) setHealthy(address string, healthy bool) {
+	v := 0.0
+	if healthy {
+		v = 1
+	}
+	m.EndpointHealthy.With(endpointLabel, address).Set(v)
+}
+
+func (m *Metrics) setCircuitState(address string, state CircuitState) {
+	m.CircuitState.With(endpointLabel, address).Set(float64(state))
+}
+
+func (m *Metrics) observe(address string, elapsed time.Duration, err error) {
+	outcome := successOutcome
+	switch {
+	case unavailable(err):
+		outcome = unavailableOutcome
+	case err != nil:
+		outcome = failedOutcome
+	}
+	m.Calls.With(endpointLabel, address, outcomeLabel, outcome).Add(1)
+	m.CallDuration.With(endpointLabel, address).Observe(elapsed.Seconds())
+}
@@ -7,52 +7,112 @@ SPDX-License-Identifier: Apache-2.0
 package queryservice
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/driver/config"
 	fdriver "github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoblocktx"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/ledger"
 )
 
+var ErrProviderStopped = fmt.Errorf("query service provider stopped")
+
+// QueryService reads the states of the committer.
+// The Context variants pass the deadline, cancellation and trace span of the context to the committer,
+// the others run under context.Background().
 type QueryService interface {
 	GetState(ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error)
 	GetStates(map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error)
+	GetStateContext(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error)
+	GetStatesContext(ctx context.Context, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error)
+}
+
+// RangeQueryService lists the states of a namespace by key range or prefix, sorted by key.
+// The returned iterators must be closed.
+type RangeQueryService interface {
+	GetStateRange(ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error)
+	GetStatesByPrefix(ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error)
+	GetStateRangeContext(ctx context.Context, ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error)
+	GetStatesByPrefixContext(ctx context.Context, ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error)
 }
 
-func NewRemoteQueryServiceFromConfig(provider protoqueryservice.QueryServiceClientProvider, configService fdriver.ConfigService) (*RemoteQueryService, error) {
+// NewRemoteQueryServiceFromConfig returns a query service spreading the queries over the configured endpoints.
+func NewRemoteQueryServiceFromConfig(provider protoqueryservice.QueryServiceClientProvider, configService fdriver.ConfigService, metrics *Metrics) (*RemoteQueryService, error) {
 	config, err := NewConfig(configService)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get config for query service: %w", err)
 	}
 
-	conn, err := GrpcClient(config)
+	conns, err := GrpcClients(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get grpc client for query service: %w", err)
 	}
 
-	return NewRemoteQueryService(config, provider.GetClient(conn)), nil
+	replicas := make([]Replica, len(conns))
+	for i, conn := range conns {
+		replicas[i] = Replica{Address: config.Endpoints[i].Address, Client: provider.GetClient(conn), Conn: conn}
+	}
+	qs := NewRemoteQueryService(config, NewBalancer(config.LoadBalancing, replicas, metrics))
+	qs.conns = conns
+	return qs, nil
 }
 
 type Provider interface {
 	Get(network, channel string) (QueryService, error)
 }
 
-func NewProvider(queryServiceClientProvider protoqueryservice.Provider, configProvider config.Provider) Provider {
+func NewProvider(
+	queryServiceClientProvider protoqueryservice.Provider,
+	configProvider config.Provider,
+	metricsProvider metrics.Provider,
+	adapterProvider protoblocktx.Provider,
+	blockDispatcherProvider *ledger.BlockDispatcherProvider,
+) Provider {
 	return &RemoteQueryServiceProvider{
 		ConfigProvider:             configProvider,
 		QueryServiceClientProvider: queryServiceClientProvider,
+		Metrics:                    NewMetrics(metricsProvider),
+		AdapterProvider:            adapterProvider,
+		BlockDispatcherProvider:    blockDispatcherProvider,
 	}
 }
 
+// RemoteQueryServiceProvider returns a query service per network and channel.
+// The services, and their connections, are created with the first Get and reused afterward,
+// until the provider is stopped.
+// When the state cache is enabled, the blocks delivered to the channel evict the states they write.
 type RemoteQueryServiceProvider struct {
 	ConfigProvider             config.Provider
 	QueryServiceClientProvider protoqueryservice.Provider
+	Metrics                    *Metrics
+	AdapterProvider            protoblocktx.Provider
+	BlockDispatcherProvider    *ledger.BlockDispatcherProvider
+
+	mu       sync.Mutex
+	services map[netCh]*RemoteQueryService
+	stopped  bool
 }
 
+type netCh struct{ network, channel string }
+
 func (r *RemoteQueryServiceProvider) Get(network, channel string) (QueryService, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil, ErrProviderStopped
+	}
+	if qs, ok := r.services[netCh{network, channel}]; ok {
+		return qs, nil
+	}
+
 	configService, err := r.ConfigProvider.GetConfig(network)
 	if err != nil {
 		return nil, fmt.Errorf("could not get mapping provider for %s: %w", channel, err)
@@ -62,7 +122,59 @@ func (r *RemoteQueryServiceProvider) Get(network, channel string) (QueryService,
 	if err != nil {
 		return nil, err
 	}
-	return NewRemoteQueryServiceFromConfig(provider, configService)
+	qs, err := NewRemoteQueryServiceFromConfig(provider, configService, r.Metrics)
+	if err != nil {
+		return nil, err
+	}
+	if qs.config.Cache.Enabled {
+		if err := r.enableCache(qs, network, channel); err != nil {
+			return nil, errors.Join(err, qs.Close())
+		}
+	}
+	if r.services == nil {
+		r.services = map[netCh]*RemoteQueryService{}
+	}
+	r.services[netCh{network, channel}] = qs
+	return qs, nil
+}
+
+func (r *RemoteQueryServiceProvider) enableCache(qs *RemoteQueryService, network, channel string) error {
+	marshaller, err := r.AdapterProvider.Get(network, channel)
+	if err != nil {
+		return err
+	}
+	dispatcher, err := r.BlockDispatcherProvider.GetBlockDispatcher(network, channel)
+	if err != nil {
+		return err
+	}
+	qs.cache = NewStateCache(qs.config.Cache.Size, marshaller, r.Metrics, channel)
+	dispatcher.AddCallback(qs.cache.OnBlock)
+	return nil
+}
+
+// Start stops the provider when the context is done.
+func (r *RemoteQueryServiceProvider) Start(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		if err := r.Stop(); err != nil {
+			logger.Errorf("failed stopping query service provider: %v", err)
+		}
+	}()
+}
+
+// Stop closes the query services returned so far. Get fails afterward.
+func (r *RemoteQueryServiceProvider) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	errs := make([]error, 0, len(r.services))
+	for key, qs := range r.services {
+		if err := qs.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("cannot close query service of [%s:%s]: %w", key.network, key.channel, err))
+		}
+	}
+	r.services = nil
+	return errors.Join(errs...)
 }
 
 func GetQueryService(sp services.Provider, network, channel string) (QueryService, error) {
@@ -0,0 +1,89 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/core/generic/driver/config"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/view/services/metrics/disabled"
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// networkConfigService only implements the calls of the query service
+type networkConfigService struct {
+	config.ConfigService
+	*configService
+}
+
+func (c *networkConfigService) UnmarshalKey(key string, rawVal interface{}) error {
+	return c.configService.UnmarshalKey(key, rawVal)
+}
+
+type configProvider struct {
+	// config is added to the endpoint of the query service
+	config map[string]any
+	calls  int
+}
+
+func (p *configProvider) GetConfig(string) (config.ConfigService, error) {
+	p.calls++
+	c := map[string]any{"queryService.Endpoints": []any{map[string]any{"address": "localhost:7001"}}}
+	maps.Copy(c, p.config)
+	return &networkConfigService{configService: newConfigService(c)}, nil
+}
+
+// clientProvider returns client, if set, instead of the clients of the connections
+type clientProvider struct {
+	client api.QueryServiceClient
+}
+
+func (p clientProvider) Get(string, string) (api.QueryServiceClientProvider, error) {
+	return p, nil
+}
+
+func (p clientProvider) GetClient(conn *grpc.ClientConn) api.QueryServiceClient {
+	if p.client != nil {
+		return p.client
+	}
+	return protoqueryservice.NewQueryServiceClientProvider().GetClient(conn)
+}
+
+func TestProviderReusesQueryServices(t *testing.T) {
+	configs := &configProvider{}
+	p := queryservice.NewProvider(clientProvider{}, configs, &disabled.Provider{}, nil, nil).(*queryservice.RemoteQueryServiceProvider)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+
+	qs1, err := p.Get("network", "channel1")
+	require.NoError(t, err)
+	qs2, err := p.Get("network", "channel1")
+	require.NoError(t, err)
+	require.Same(t, qs1, qs2)
+	require.Equal(t, 1, configs.calls)
+
+	qs3, err := p.Get("network", "channel2")
+	require.NoError(t, err)
+	require.NotSame(t, qs1, qs3)
+
+	// the services are closed when the node stops
+	cancel()
+	require.Eventually(t, func() bool {
+		_, err := p.Get("network", "channel1")
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = p.Get("network", "channel1")
+	require.ErrorIs(t, err, queryservice.ErrProviderStopped)
+	require.Error(t, qs1.(*queryservice.RemoteQueryService).Close(), "connections already closed")
+}
@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/utils/collections/iterators"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+)
+
+// NewProxyStore wraps the local vault store so that the states missing locally are read from the committer.
+// Local states always win: they are written by the commit pipeline from the same blocks the committer serves,
+// so that the versions and values returned by both sources agree.
+// The states read from the committer are not stored locally.
+// Range queries merge the local states with the ones of the committer, when the query service is a RangeQueryService.
+// When the query service is a SessionProvider, the reads of a locked reader, hence of a transaction,
+// share a session at the isolation level of the reader, and the session is closed with the reader.
+func NewProxyStore(store vault.CachedVaultStore, queryService QueryService) *proxyStore {
+	return &proxyStore{CachedVaultStore: store, queryService: queryService}
+}
+
+type proxyStore struct {
+	vault.CachedVaultStore
+	queryService QueryService
+}
+
+func (s *proxyStore) GetState(ctx context.Context, namespace driver.Namespace, key driver.PKey) (*driver.VaultRead, error) {
+	return getState(ctx, s.CachedVaultStore, s.queryService, namespace, key)
+}
+
+func (s *proxyStore) GetStates(ctx context.Context, namespace driver.Namespace, keys ...driver.PKey) (driver.TxStateIterator, error) {
+	return getStates(ctx, s.CachedVaultStore, s.queryService, namespace, keys...)
+}
+
+func (s *proxyStore) GetStateRange(ctx context.Context, namespace driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return getStateRange(ctx, s.CachedVaultStore, s.queryService, namespace, startKey, endKey)
+}
+
+func (s *proxyStore) NewTxLockVaultReader(ctx context.Context, txID driver.TxID, isolationLevel driver.IsolationLevel) (driver.LockedVaultReader, error) {
+	r, err := s.CachedVaultStore.NewTxLockVaultReader(ctx, txID, isolationLevel)
+	if err != nil {
+		return nil, err
+	}
+	return s.newReader(r, isolationLevel), nil
+}
+
+func (s *proxyStore) NewGlobalLockVaultReader(ctx context.Context) (driver.LockedVaultReader, error) {
+	r, err := s.CachedVaultStore.NewGlobalLockVaultReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.newReader(r, driver.LevelDefault), nil
+}
+
+func (s *proxyStore) newReader(r driver.LockedVaultReader, isolationLevel driver.IsolationLevel) *proxyReader {
+	sp, ok := s.queryService.(SessionProvider)
+	if !ok {
+		return &proxyReader{LockedVaultReader: r, queryService: s.queryService}
+	}
+	session := sp.NewSession(isolationLevel)
+	return &proxyReader{LockedVaultReader: r, queryService: session, session: session}
+}
+
+type proxyReader struct {
+	driver.LockedVaultReader
+	queryService QueryService
+	session      Session
+}
+
+// Done releases the local lock and ends the session with the committer.
+func (r *proxyReader) Done() error {
+	err := r.LockedVaultReader.Done()
+	if r.session != nil {
+		err = errors.Join(err, r.session.Close())
+	}
+	return err
+}
+
+func (r *proxyReader) GetState(ctx context.Context, namespace driver.Namespace, key driver.PKey) (*driver.VaultRead, error) {
+	return getState(ctx, r.LockedVaultReader, r.queryService, namespace, key)
+}
+
+func (r *proxyReader) GetStates(ctx context.Context, namespace driver.Namespace, keys ...driver.PKey) (driver.TxStateIterator, error) {
+	return getStates(ctx, r.LockedVaultReader, r.queryService, namespace, keys...)
+}
+
+func (r *proxyReader) GetStateRange(ctx context.Context, namespace driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return getStateRange(ctx, r.LockedVaultReader, r.queryService, namespace, startKey, endKey)
+}
+
+func getState(ctx context.Context, local driver.VaultReader, queryService QueryService, namespace driver.Namespace, key driver.PKey) (*driver.VaultRead, error) {
+	read, err := local.GetState(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if found(read) {
+		return read, nil
+	}
+
+	logger.Debugf("state [%s:%s] not found locally, query committer", namespace, key)
+	v, err := queryService.GetStateContext(ctx, namespace, key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return read, nil
+	}
+	return &driver.VaultRead{Key: key, Raw: v.Raw, Version: v.Version}, nil
+}
+
+func getStates(ctx context.Context, local driver.VaultReader, queryService QueryService, namespace driver.Namespace, keys ...driver.PKey) (driver.TxStateIterator, error) {
+	it, err := local.GetStates(ctx, namespace, keys...)
+	if err != nil {
+		return nil, err
+	}
+	reads, err := iterators.ReadAllPointers(it)
+	if err != nil {
+		return nil, err
+	}
+
+	localReads := make(map[driver.PKey]*driver.VaultRead, len(reads))
+	for _, read := range reads {
+		if found(read) {
+			localReads[read.Key] = read
+		}
+	}
+	missing := make([]driver.PKey, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := localReads[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return iterators.Slice(reads), nil
+	}
+
+	logger.Debugf("[%d] states of [%s] not found locally, query committer", len(missing), namespace)
+	remote, err := queryService.GetStatesContext(ctx, map[driver.Namespace][]driver.PKey{namespace: missing})
+	if err != nil {
+		return nil, err
+	}
+
+	// return the states in the order of the requested keys
+	result := make([]*driver.VaultRead, 0, len(keys))
+	for _, key := range keys {
+		if read, ok := localReads[key]; ok {
+			result = append(result, read)
+		} else if v, ok := remote[namespace][key]; ok {
+			result = append(result, &driver.VaultRead{Key: key, Raw: v.Raw, Version: v.Version})
+		}
+	}
+	return iterators.Slice(result), nil
+}
+
+// getStateRange merges the states of the range found locally with the ones of the committer, sorted by key.
+// Only the local states are returned, when the query service does not support range queries.
+func getStateRange(ctx context.Context, local driver.VaultReader, queryService QueryService, namespace driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	it, err := local.GetStateRange(ctx, namespace, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	rqs, ok := queryService.(RangeQueryService)
+	if !ok {
+		return it, nil
+	}
+	reads, err := iterators.ReadAllPointers(it)
+	if err != nil {
+		return nil, err
+	}
+	// the local vault does not sort the range
+	slices.SortFunc(reads, func(a, b *driver.VaultRead) int { return strings.Compare(a.Key, b.Key) })
+
+	remote, err := rqs.GetStateRangeContext(ctx, namespace, startKey, endKey)
+	if errors.Is(err, protoqueryservice.ErrNotSupported) {
+		logger.Debugf("committer does not support range queries, return local states of [%s]", namespace)
+		return iterators.Slice(reads), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mergedIterator{local: reads, remote: remote}, nil
+}
+
+// mergedIterator merges the local states and the remote ones, both sorted by key.
+// When both have a key, the local state is returned.
+type mergedIterator struct {
+	local  []*driver.VaultRead
+	remote driver.TxStateIterator
+	// next is the next remote state, nil when it must be received
+	next       *driver.VaultRead
+	remoteDone bool
+}
+
+func (it *mergedIterator) Next() (*driver.VaultRead, error) {
+	if it.next == nil && !it.remoteDone {
+		read, err := it.remote.Next()
+		if err != nil {
+			return nil, err
+		}
+		it.next = read
+		it.remoteDone = read == nil
+	}
+
+	switch {
+	case it.next == nil && len(it.local) == 0:
+		return nil, nil
+	case it.next == nil || (len(it.local) != 0 && it.local[0].Key <= it.next.Key):
+		read := it.local[0]
+		it.local = it.local[1:]
+		if it.next != nil && it.next.Key == read.Key {
+			it.next = nil
+		}
+		return read, nil
+	default:
+		read := it.next
+		it.next = nil
+		return read, nil
+	}
+}
+
+func (it *mergedIterator) Close() {
+	it.remote.Close()
+}
+
+func found(read *driver.VaultRead) bool {
+	return read != nil && (len(read.Raw) != 0 || len(read.Version) != 0)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/utils/collections/iterators"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+)
+
+type mapQueryService struct {
+	states  map[driver.Namespace]map[driver.PKey]driver.VaultValue
+	queried [][]driver.PKey
+	// ctx is the context of the last query
+	ctx context.Context
+}
+
+func (s *mapQueryService) GetState(ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	return s.GetStateContext(context.Background(), ns, key)
+}
+
+func (s *mapQueryService) GetStates(m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+	return s.GetStatesContext(context.Background(), m)
+}
+
+func (s *mapQueryService) GetStateContext(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	res, err := s.GetStatesContext(ctx, map[driver.Namespace][]driver.PKey{ns: {key}})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := res[ns][key]
+	if !ok {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func (s *mapQueryService) GetStatesContext(ctx context.Context, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+	s.ctx = ctx
+	res := make(map[driver.Namespace]map[driver.PKey]driver.VaultValue)
+	for ns, keys := range m {
+		s.queried = append(s.queried, keys)
+		res[ns] = make(map[driver.PKey]driver.VaultValue)
+		for _, key := range keys {
+			if v, ok := s.states[ns][key]; ok {
+				res[ns][key] = v
+			}
+		}
+	}
+	return res, nil
+}
+
+func setupProxy(t *testing.T) (driver.VaultStore, *mapQueryService) {
+	t.Helper()
+	store, err := vault.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	err = store.Store(context.Background(), []driver.TxID{"tx1"}, driver.Writes{
+		"ns1": {"local": {Raw: []byte("local"), Version: types.VersionNumber(3).Bytes()}},
+	}, nil)
+	require.NoError(t, err)
+
+	qs := &mapQueryService{states: map[driver.Namespace]map[driver.PKey]driver.VaultValue{
+		"ns1": {
+			"local":  {Raw: []byte("stale"), Version: types.VersionNumber(1).Bytes()},
+			"remote": {Raw: []byte("remote"), Version: types.VersionNumber(7).Bytes()},
+		},
+	}}
+	return queryservice.NewProxyStore(vault.NewCachedVault(store, 0), qs), qs
+}
+
+func TestProxyStoreGetState(t *testing.T) {
+	store, qs := setupProxy(t)
+	ctx := context.Background()
+
+	read, err := store.GetState(ctx, "ns1", "local")
+	require.NoError(t, err)
+	require.Equal(t, []byte("local"), read.Raw)
+	require.Equal(t, types.VersionNumber(3).Bytes(), read.Version)
+	require.Empty(t, qs.queried)
+
+	read, err = store.GetState(ctx, "ns1", "remote")
+	require.NoError(t, err)
+	require.Equal(t, &driver.VaultRead{Key: "remote", Raw: []byte("remote"), Version: types.VersionNumber(7).Bytes()}, read)
+	require.Equal(t, [][]driver.PKey{{"remote"}}, qs.queried)
+
+	read, err = store.GetState(ctx, "ns1", "missing")
+	require.NoError(t, err)
+	require.Nil(t, read)
+
+	// the context of the vault reaches the query service
+	type key struct{}
+	ctx = context.WithValue(ctx, key{}, "value")
+	_, err = store.GetState(ctx, "ns1", "remote")
+	require.NoError(t, err)
+	require.Equal(t, "value", qs.ctx.Value(key{}))
+}
+
+func TestProxyStoreGetStates(t *testing.T) {
+	store, qs := setupProxy(t)
+
+	it, err := store.GetStates(context.Background(), "ns1", "remote", "missing", "local")
+	require.NoError(t, err)
+	reads, err := iterators.ReadAllValues(it)
+	require.NoError(t, err)
+	require.Equal(t, []driver.VaultRead{
+		{Key: "remote", Raw: []byte("remote"), Version: types.VersionNumber(7).Bytes()},
+		{Key: "local", Raw: []byte("local"), Version: types.VersionNumber(3).Bytes()},
+	}, reads)
+	require.Equal(t, [][]driver.PKey{{"remote", "missing"}}, qs.queried)
+}
+
+func TestProxyStoreLockedReader(t *testing.T) {
+	store, qs := setupProxy(t)
+	ctx := context.Background()
+
+	r, err := store.NewTxLockVaultReader(ctx, "tx2", driver.LevelDefault)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, r.Done()) }()
+
+	read, err := r.GetState(ctx, "ns1", "remote")
+	require.NoError(t, err)
+	require.Equal(t, []byte("remote"), read.Raw)
+	require.Len(t, qs.queried, 1)
+}
+
+type sessionQueryService struct {
+	*mapQueryService
+	sessions []*recordingSession
+}
+
+type recordingSession struct {
+	queryservice.QueryService
+	isolationLevel driver.IsolationLevel
+	closed         bool
+}
+
+func (s *recordingSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *sessionQueryService) NewSession(isolationLevel driver.IsolationLevel) queryservice.Session {
+	session := &recordingSession{QueryService: s.mapQueryService, isolationLevel: isolationLevel}
+	s.sessions = append(s.sessions, session)
+	return session
+}
+
+func TestProxyStoreSession(t *testing.T) {
+	local, err := vault.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = local.Close() })
+	qs := &sessionQueryService{mapQueryService: &mapQueryService{}}
+	store := queryservice.NewProxyStore(vault.NewCachedVault(local, 0), qs)
+	ctx := context.Background()
+
+	r, err := store.NewTxLockVaultReader(ctx, "tx1", driver.LevelSerializable)
+	require.NoError(t, err)
+	_, err = r.GetState(ctx, "ns1", "key1")
+	require.NoError(t, err)
+	require.Len(t, qs.sessions, 1)
+	require.Equal(t, driver.LevelSerializable, qs.sessions[0].isolationLevel)
+	require.False(t, qs.sessions[0].closed)
+
+	require.NoError(t, r.Done())
+	require.True(t, qs.sessions[0].closed)
+}
@@ -8,12 +8,14 @@ package queryservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/services/logging"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -25,61 +27,180 @@ var (
 type RemoteQueryService struct {
 	client protoqueryservice.QueryServiceClient
 	config *Config
+	// conns are the connections owned by the service, released by Close
+	conns []*grpc.ClientConn
+	// batcher coalesces the calls to GetState, nil if batching is disabled
+	batcher *batcher
+	// cache keeps the states read, nil if caching is disabled
+	cache *stateCache
+	// retry tells which failed queries are tried again, nil if retries are disabled
+	retry *retryPolicy
 }
 
 func NewRemoteQueryService(config *Config, client protoqueryservice.QueryServiceClient) *RemoteQueryService {
-	return &RemoteQueryService{
+	s := &RemoteQueryService{
 		client: client,
 		config: config,
+		retry:  newRetryPolicy(config.Retry),
 	}
+	if config.Batching.Enabled {
+		s.batcher = newBatcher(func(ctx context.Context, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+			return s.query(ctx, nil, m)
+		}, config.Batching.MaxWait, config.Batching.MaxKeys)
+	}
+	return s
+}
+
+// Close stops the health checks of the endpoints and closes the connections of the service.
+// The service must not be used afterward.
+func (s *RemoteQueryService) Close() error {
+	if b, ok := s.client.(*Balancer); ok {
+		b.Close()
+	}
+	errs := make([]error, len(s.conns))
+	for i, conn := range s.conns {
+		errs[i] = conn.Close()
+	}
+	return errors.Join(errs...)
 }
 
 func (s *RemoteQueryService) GetState(ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	return s.GetStateContext(context.Background(), ns, key)
+}
+
+// GetStateContext returns the state of the key, or nil if the key does not exist.
+// The deadline, cancellation and trace span of the context are passed to the committer,
+// the query timeout of the configuration applying if it expires first.
+func (s *RemoteQueryService) GetStateContext(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
 	if len(ns) == 0 || len(key) == 0 {
 		return nil, ErrInvalidQueryInput
 	}
+	if s.cache == nil {
+		return s.getState(ctx, ns, key)
+	}
+
+	if v, ok := s.cache.get(ns, key); ok {
+		return v, nil
+	}
+	gen := s.cache.generation()
+	v, err := s.getState(ctx, ns, key)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(gen, ns, key, v)
+	return v, nil
+}
+
+// getState reads the state from the committer, in a batch if batching is enabled.
+func (s *RemoteQueryService) getState(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	if s.batcher != nil {
+		return s.batcher.get(ctx, ns, key)
+	}
 
 	m := map[driver.Namespace][]driver.PKey{
 		ns: {key},
 	}
 
 	logger.Debugf("QS GetState %v %v", ns, key)
-	res, err := s.GetStates(m)
+	res, err := s.query(ctx, nil, m)
 	if err != nil {
 		return nil, err
 	}
+	return lookup(res, ns, key), nil
+}
 
+// lookup returns the value of the key in the result of a query, or nil when the key does not exist.
+func lookup(res map[driver.Namespace]map[driver.PKey]driver.VaultValue, ns driver.Namespace, key driver.PKey) *driver.VaultValue {
 	// ensure that ns exists
 	if _, ok := res[ns]; !ok {
-		return nil, nil
+		return nil
 	}
 
 	// key does not exist
 	r, ok := res[ns][key]
 	if !ok {
-		return nil, nil
+		return nil
 	}
 
-	return &r, nil
+	return &r
 }
 
 func (s *RemoteQueryService) GetStates(m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
-	return s.query(m)
+	return s.GetStatesContext(context.Background(), m)
 }
 
-func (s *RemoteQueryService) query(m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+// GetStatesContext returns the states of the keys that exist, by namespace, see GetStateContext.
+func (s *RemoteQueryService) GetStatesContext(ctx context.Context, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+	if s.cache == nil {
+		return s.query(ctx, nil, m)
+	}
+
+	// only the states missing in the cache are queried
+	if len(m) == 0 {
+		return nil, ErrInvalidQueryInput
+	}
+	res := make(map[driver.Namespace]map[driver.PKey]driver.VaultValue, len(m))
+	misses := make(map[driver.Namespace][]driver.PKey)
+	for ns, keys := range m {
+		if len(ns) == 0 || len(keys) == 0 {
+			return nil, ErrInvalidQueryInput
+		}
+		for _, key := range keys {
+			if len(key) == 0 {
+				return nil, ErrInvalidQueryInput
+			}
+			v, ok := s.cache.get(ns, key)
+			switch {
+			case !ok:
+				misses[ns] = append(misses[ns], key)
+			case v != nil:
+				if _, ok := res[ns]; !ok {
+					res[ns] = map[driver.PKey]driver.VaultValue{}
+				}
+				res[ns][key] = *v
+			}
+		}
+	}
+	if len(misses) == 0 {
+		return res, nil
+	}
+
+	gen := s.cache.generation()
+	fetched, err := s.query(ctx, nil, misses)
+	if err != nil {
+		return nil, err
+	}
+	for ns, keys := range misses {
+		for _, key := range keys {
+			v := lookup(fetched, ns, key)
+			s.cache.put(gen, ns, key, v)
+			if v == nil {
+				continue
+			}
+			if _, ok := res[ns]; !ok {
+				res[ns] = map[driver.PKey]driver.VaultValue{}
+			}
+			res[ns][key] = *v
+		}
+	}
+	return res, nil
+}
+
+func (s *RemoteQueryService) query(ctx context.Context, view protoqueryservice.View, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
 	logger.Debugf("QS GetState: query input %v", m)
 
-	q, err := createQuery(m)
+	q, err := createQuery(view, m)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.QueryTimeout)
+	ctx, cancel := context.WithTimeout(ctx, s.config.QueryTimeout)
 	defer cancel()
 
 	now := time.Now()
-	res, err := s.client.GetRows(ctx, q)
+	res, err := retry(ctx, s.retry, func() (protoqueryservice.Rows, error) {
+		return s.client.GetRows(ctx, q)
+	})
 	if err != nil {
 		logger.Warnf("QS GetState: error calling getRows: %v", err)
 		return nil, err
@@ -91,10 +212,29 @@ func (s *RemoteQueryService) query(m map[driver.Namespace][]driver.PKey) (map[dr
 	return result, nil
 }
 
+// GetNamespaceVersions returns the version of the policy of each namespace known to the committer.
+// It returns protoqueryservice.ErrNotSupported if the committer does not serve policies.
+func (s *RemoteQueryService) GetNamespaceVersions(ctx context.Context) (map[driver.Namespace]driver.RawVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.QueryTimeout)
+	defer cancel()
+
+	res, err := s.client.GetPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[driver.Namespace]driver.RawVersion, len(res.GetPolicies()))
+	for _, p := range res.GetPolicies() {
+		versions[p.GetNamespace()] = p.GetVersion()
+	}
+	logger.Debugf("QS GetPolicies: got versions of [%d] namespaces", len(versions))
+	return versions, nil
+}
+
 // createQuery converts an input map into a `protoqueryservice.Query`.
 // It returns a `ErrInvalidQueryInput` error if the input is invalid, in particular, if the input is empty
 // of a namespace does not contain any keys.
-func createQuery(m map[driver.Namespace][]driver.PKey) (protoqueryservice.Query, error) {
+func createQuery(view protoqueryservice.View, m map[driver.Namespace][]driver.PKey) (protoqueryservice.Query, error) {
 	if len(m) == 0 {
 		return nil, ErrInvalidQueryInput
 	}
@@ -121,7 +261,7 @@ func createQuery(m map[driver.Namespace][]driver.PKey) (protoqueryservice.Query,
 		namespaces = append(namespaces, protoqueryservice.NewQueryNamespace(nsName, nsKeys))
 	}
 
-	return protoqueryservice.NewQuery(nil, namespaces), nil
+	return protoqueryservice.NewQuery(view, namespaces), nil
 }
 
 // createResult converts a response (`protoqueryservice.Rows`) into a 2-dim map data structure.
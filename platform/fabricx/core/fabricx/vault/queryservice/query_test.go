@@ -7,16 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package queryservice_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoblocktx"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
 	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 )
 
 func setupTest(tb testing.TB) (*queryservice.RemoteQueryService, *protoqueryservicefakes.FakeQueryServiceClient) {
@@ -369,4 +373,60 @@ func TestQueryService(t *testing.T) {
 		_, err := qs.GetState("ns", "key1")
 		require.ErrorIs(t, err, expectedError)
 	})
+
+	t.Run("GetStateContext passes the context to the committer", func(t *testing.T) {
+		t.Parallel()
+		qs, fake := setupTest(t)
+
+		spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+		ctx, cancel := context.WithTimeout(trace.ContextWithSpanContext(context.Background(), spanCtx), time.Second)
+		defer cancel()
+		fake.GetRowsStub = func(rpcCtx context.Context, q *protoqueryservice.Query, opts ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+			require.Equal(t, spanCtx, trace.SpanContextFromContext(rpcCtx))
+			callerDeadline, _ := ctx.Deadline()
+			deadline, ok := rpcCtx.Deadline()
+			require.True(t, ok)
+			require.Equal(t, callerDeadline, deadline)
+			return echoRows(rpcCtx, q, opts...)
+		}
+		_, err := qs.GetStateContext(ctx, "ns1", "key1")
+		require.NoError(t, err)
+		_, err = qs.GetStatesContext(ctx, map[driver.Namespace][]driver.PKey{"ns1": {"key1"}})
+		require.NoError(t, err)
+		require.Equal(t, 2, fake.GetRowsCallCount())
+
+		// the calls stop with the caller
+		fake.GetRowsStub = func(rpcCtx context.Context, _ *protoqueryservice.Query, _ ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+			<-rpcCtx.Done()
+			return nil, rpcCtx.Err()
+		}
+		cancel()
+		_, err = qs.GetStateContext(ctx, "ns1", "key1")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("GetNamespaceVersions passes the context to the committer", func(t *testing.T) {
+		t.Parallel()
+		qs, fake := setupTest(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		fake.GetPoliciesStub = func(rpcCtx context.Context, _ *protoqueryservice.Empty, _ ...grpc.CallOption) (*protoblocktx.Policies, error) {
+			callerDeadline, _ := ctx.Deadline()
+			deadline, ok := rpcCtx.Deadline()
+			require.True(t, ok)
+			require.Equal(t, callerDeadline, deadline)
+			return &protoblocktx.Policies{Policies: []*protoblocktx.PolicyItem{{Namespace: "ns1", Version: types.VersionNumber(2).Bytes()}}}, nil
+		}
+		versions, err := qs.GetNamespaceVersions(ctx)
+		require.NoError(t, err)
+		require.Equal(t, map[driver.Namespace]driver.RawVersion{"ns1": types.VersionNumber(2).Bytes()}, versions)
+
+		cancel()
+		fake.GetPoliciesStub = func(rpcCtx context.Context, _ *protoqueryservice.Empty, _ ...grpc.CallOption) (*protoblocktx.Policies, error) {
+			return nil, rpcCtx.Err()
+		}
+		_, err = qs.GetNamespaceVersions(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
 }
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+)
+
+// GetStateRange returns the states of the namespace with keys in [startKey, endKey), sorted by key.
+// An empty key leaves the range open on that side.
+// The committer streams the states a page at a time, as the iterator advances;
+// the iterator must be closed to release the stream.
+func (s *RemoteQueryService) GetStateRange(ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return s.GetStateRangeContext(context.Background(), ns, startKey, endKey)
+}
+
+// GetStateRangeContext is GetStateRange under the passed context, which also bounds the stream.
+func (s *RemoteQueryService) GetStateRangeContext(ctx context.Context, ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return s.queryRange(ctx, protoqueryservice.NewRangeQuery(nil, ns, []byte(startKey), []byte(endKey), s.config.RangePageSize))
+}
+
+// GetStatesByPrefix returns the states of the namespace with keys starting with prefix, sorted by key.
+func (s *RemoteQueryService) GetStatesByPrefix(ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error) {
+	return s.GetStatesByPrefixContext(context.Background(), ns, prefix)
+}
+
+// GetStatesByPrefixContext is GetStatesByPrefix under the passed context, which also bounds the stream.
+func (s *RemoteQueryService) GetStatesByPrefixContext(ctx context.Context, ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error) {
+	return s.queryRange(ctx, protoqueryservice.NewPrefixQuery(nil, ns, []byte(prefix), s.config.RangePageSize))
+}
+
+func (s *RemoteQueryService) queryRange(ctx context.Context, q protoqueryservice.RangeQuery) (driver.TxStateIterator, error) {
+	if len(q.GetNsId()) == 0 {
+		return nil, ErrInvalidQueryInput
+	}
+	logger.Debugf("QS GetRowsRange: [%s] from [%s] to [%s]", q.GetNsId(), q.GetStartKey(), q.GetEndKey())
+
+	// the timeout covers the whole stream, as it does for the other queries
+	ctx, cancel := context.WithTimeout(ctx, s.config.QueryTimeout)
+	stream, err := s.client.GetRowsRange(ctx, q)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &rangeIterator{stream: stream, cancel: cancel}, nil
+}
+
+func (s *session) GetStateRange(ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return s.GetStateRangeContext(context.Background(), ns, startKey, endKey)
+}
+
+func (s *session) GetStateRangeContext(ctx context.Context, ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	view, err := s.beginView(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.service.queryRange(ctx, protoqueryservice.NewRangeQuery(view, ns, []byte(startKey), []byte(endKey), s.service.config.RangePageSize))
+}
+
+func (s *session) GetStatesByPrefix(ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error) {
+	return s.GetStatesByPrefixContext(context.Background(), ns, prefix)
+}
+
+func (s *session) GetStatesByPrefixContext(ctx context.Context, ns driver.Namespace, prefix driver.PKey) (driver.TxStateIterator, error) {
+	view, err := s.beginView(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.service.queryRange(ctx, protoqueryservice.NewPrefixQuery(view, ns, []byte(prefix), s.service.config.RangePageSize))
+}
+
+// rangeIterator returns the rows of a stream, receiving the next page when the current one is consumed.
+type rangeIterator struct {
+	stream protoqueryservice.RowsStream
+	cancel context.CancelFunc
+	page   []protoqueryservice.Row
+	done   bool
+}
+
+func (it *rangeIterator) Next() (*driver.VaultRead, error) {
+	for len(it.page) == 0 {
+		if it.done {
+			return nil, nil
+		}
+		page, err := it.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			it.done = true
+			it.cancel()
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		it.page = page
+	}
+
+	row := it.page[0]
+	it.page = it.page[1:]
+	return &driver.VaultRead{Key: string(row.GetKey()), Raw: row.GetValue(), Version: row.GetVersion()}, nil
+}
+
+func (it *rangeIterator) Close() {
+	it.cancel()
+}
@@ -0,0 +1,235 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/utils/collections/iterators"
+	"github.com/hyperledger-labs/fabric-smart-client/platform/fabric/services/storage/vault"
+	api "github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/types"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// pageStream streams the passed pages, and then io.EOF
+type pageStream struct {
+	pages [][]api.Row
+}
+
+func (s *pageStream) Recv() ([]api.Row, error) {
+	if len(s.pages) == 0 {
+		return nil, io.EOF
+	}
+	page := s.pages[0]
+	s.pages = s.pages[1:]
+	return page, nil
+}
+
+// rangeClient streams the passed pages for the range queries, and serves the other calls with the v2 adapter
+type rangeClient struct {
+	api.QueryServiceClient
+	pages   [][]api.Row
+	queries []api.RangeQuery
+}
+
+func (c *rangeClient) GetRowsRange(_ context.Context, in api.RangeQuery, _ ...grpc.CallOption) (api.RowsStream, error) {
+	c.queries = append(c.queries, in)
+	return &pageStream{pages: c.pages}, nil
+}
+
+func row(key string, version uint64) *protoqueryservice.Row {
+	return &protoqueryservice.Row{Key: []byte(key), Value: []byte("value of " + key), Version: types.VersionNumber(version).Bytes()}
+}
+
+func setupRangeTest(t *testing.T, config *queryservice.Config, pages ...[]api.Row) (*queryservice.RemoteQueryService, *rangeClient) {
+	t.Helper()
+	client := &protoqueryservicefakes.FakeQueryServiceClient{}
+	client.BeginViewReturns(&protoqueryservice.View{Id: "view1"}, nil)
+	rc := &rangeClient{QueryServiceClient: protoqueryservice.NewServiceAdapter(client), pages: pages}
+	return queryservice.NewRemoteQueryService(config, rc), rc
+}
+
+func TestGetStateRange(t *testing.T) {
+	qs, client := setupRangeTest(t, &queryservice.Config{QueryTimeout: 5 * time.Second, RangePageSize: 2},
+		[]api.Row{row("key1", 1), row("key2", 2)},
+		[]api.Row{row("key3", 3)},
+	)
+
+	it, err := qs.GetStateRange("ns1", "key1", "key4")
+	require.NoError(t, err)
+	defer it.Close()
+	reads, err := iterators.ReadAllValues(it)
+	require.NoError(t, err)
+	require.Equal(t, []driver.VaultRead{
+		{Key: "key1", Raw: []byte("value of key1"), Version: types.VersionNumber(1).Bytes()},
+		{Key: "key2", Raw: []byte("value of key2"), Version: types.VersionNumber(2).Bytes()},
+		{Key: "key3", Raw: []byte("value of key3"), Version: types.VersionNumber(3).Bytes()},
+	}, reads)
+
+	require.Len(t, client.queries, 1)
+	q := client.queries[0]
+	require.Equal(t, "ns1", q.GetNsId())
+	require.Equal(t, []byte("key1"), q.GetStartKey())
+	require.Equal(t, []byte("key4"), q.GetEndKey())
+	require.Equal(t, uint32(2), q.GetPageSize())
+	require.Nil(t, q.GetView())
+}
+
+func TestGetStatesByPrefix(t *testing.T) {
+	table := []struct {
+		prefix string
+		end    []byte
+	}{
+		{prefix: "token", end: []byte("tokeo")},
+		{prefix: "a\xff", end: []byte("b")},
+		{prefix: "\xff\xff", end: nil},
+	}
+	for _, tc := range table {
+		qs, client := setupRangeTest(t, &queryservice.Config{QueryTimeout: 5 * time.Second})
+
+		it, err := qs.GetStatesByPrefix("ns1", tc.prefix)
+		require.NoError(t, err)
+		read, err := it.Next()
+		require.NoError(t, err)
+		require.Nil(t, read)
+		it.Close()
+
+		q := client.queries[0]
+		require.Equal(t, []byte(tc.prefix), q.GetStartKey())
+		require.Equal(t, tc.end, q.GetEndKey())
+	}
+}
+
+func TestGetStateRangeInvalidInput(t *testing.T) {
+	qs, client := setupRangeTest(t, &queryservice.Config{QueryTimeout: 5 * time.Second})
+
+	_, err := qs.GetStateRange("", "key1", "key2")
+	require.ErrorIs(t, err, queryservice.ErrInvalidQueryInput)
+	require.Empty(t, client.queries)
+}
+
+func TestSessionGetStateRange(t *testing.T) {
+	qs, client := setupRangeTest(t, &queryservice.Config{QueryTimeout: 5 * time.Second, View: queryservice.ViewConfig{Enabled: true}})
+
+	s := qs.NewSession(driver.LevelDefault)
+	it, err := s.(queryservice.RangeQueryService).GetStateRange("ns1", "", "")
+	require.NoError(t, err)
+	it.Close()
+	require.NoError(t, s.Close())
+
+	require.Equal(t, "view1", client.queries[0].GetView().GetId())
+}
+
+type rangeQueryService struct {
+	*mapQueryService
+	closed bool
+}
+
+func (s *rangeQueryService) GetStateRange(ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	return s.GetStateRangeContext(context.Background(), ns, startKey, endKey)
+}
+
+func (s *rangeQueryService) GetStateRangeContext(_ context.Context, ns driver.Namespace, startKey, endKey driver.PKey) (driver.TxStateIterator, error) {
+	var reads []*driver.VaultRead
+	for _, key := range []driver.PKey{"key1", "key2", "key3"} {
+		v, ok := s.states[ns][key]
+		if ok && key >= startKey && (endKey == "" || key < endKey) {
+			reads = append(reads, &driver.VaultRead{Key: key, Raw: v.Raw, Version: v.Version})
+		}
+	}
+	return &closeRecorder{TxStateIterator: iterators.Slice(reads), closed: &s.closed}, nil
+}
+
+func (s *rangeQueryService) GetStatesByPrefix(driver.Namespace, driver.PKey) (driver.TxStateIterator, error) {
+	panic("not used")
+}
+
+func (s *rangeQueryService) GetStatesByPrefixContext(context.Context, driver.Namespace, driver.PKey) (driver.TxStateIterator, error) {
+	panic("not used")
+}
+
+type closeRecorder struct {
+	driver.TxStateIterator
+	closed *bool
+}
+
+func (it *closeRecorder) Close() {
+	*it.closed = true
+	it.TxStateIterator.Close()
+}
+
+func TestProxyStoreGetStateRange(t *testing.T) {
+	local, err := vault.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = local.Close() })
+	ctx := context.Background()
+	err = local.Store(ctx, []driver.TxID{"tx1"}, driver.Writes{
+		"ns1": {
+			"key2": {Raw: []byte("local2"), Version: types.VersionNumber(5).Bytes()},
+			"key4": {Raw: []byte("local4"), Version: types.VersionNumber(5).Bytes()},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	qs := &rangeQueryService{mapQueryService: &mapQueryService{states: map[driver.Namespace]map[driver.PKey]driver.VaultValue{
+		"ns1": {
+			"key1": {Raw: []byte("remote1"), Version: types.VersionNumber(1).Bytes()},
+			"key2": {Raw: []byte("stale"), Version: types.VersionNumber(1).Bytes()},
+			"key3": {Raw: []byte("remote3"), Version: types.VersionNumber(1).Bytes()},
+		},
+	}}}
+	store := queryservice.NewProxyStore(vault.NewCachedVault(local, 0), qs)
+
+	// local states win, and are merged in key order with the ones of the committer
+	it, err := store.GetStateRange(ctx, "ns1", "key", "key5")
+	require.NoError(t, err)
+	reads, err := iterators.ReadAllValues(it)
+	require.NoError(t, err)
+	require.Equal(t, []driver.VaultRead{
+		{Key: "key1", Raw: []byte("remote1"), Version: types.VersionNumber(1).Bytes()},
+		{Key: "key2", Raw: []byte("local2"), Version: types.VersionNumber(5).Bytes()},
+		{Key: "key3", Raw: []byte("remote3"), Version: types.VersionNumber(1).Bytes()},
+		{Key: "key4", Raw: []byte("local4"), Version: types.VersionNumber(5).Bytes()},
+	}, reads)
+	require.True(t, qs.closed)
+
+	// without range support, only the local states are returned
+	it, err = queryservice.NewProxyStore(vault.NewCachedVault(local, 0), qs.mapQueryService).GetStateRange(ctx, "ns1", "key", "key5")
+	require.NoError(t, err)
+	reads, err = iterators.ReadAllValues(it)
+	require.NoError(t, err)
+	require.Len(t, reads, 2)
+}
+
+func TestProxyStoreGetStateRangeNotSupported(t *testing.T) {
+	local, err := vault.OpenMemoryVault()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = local.Close() })
+	ctx := context.Background()
+	err = local.Store(ctx, []driver.TxID{"tx1"}, driver.Writes{
+		"ns1": {"key2": {Raw: []byte("local2"), Version: types.VersionNumber(5).Bytes()}},
+	}, nil)
+	require.NoError(t, err)
+
+	// the committer query service has no range queries
+	qs := queryservice.NewRemoteQueryService(&queryservice.Config{QueryTimeout: 5 * time.Second}, protoqueryservice.NewServiceAdapter(&protoqueryservicefakes.FakeQueryServiceClient{}))
+
+	it, err := queryservice.NewProxyStore(vault.NewCachedVault(local, 0), qs).GetStateRange(ctx, "ns1", "key", "key5")
+	require.NoError(t, err)
+	reads, err := iterators.ReadAllValues(it)
+	require.NoError(t, err)
+	require.Equal(t, []driver.VaultRead{{Key: "key2", Raw: []byte("local2"), Version: types.VersionNumber(5).Bytes()}}, reads)
+}
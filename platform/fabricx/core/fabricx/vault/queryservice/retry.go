@@ -0,0 +1,112 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codeNames maps the normalized names of the gRPC status codes to the codes, see normalizeCode
+var codeNames = func() map[string]codes.Code {
+	m := make(map[string]codes.Code, codes.Unauthenticated+1)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[normalizeCode(c.String())] = c
+	}
+	return m
+}()
+
+// normalizeCode lets the codes be configured as Unavailable, unavailable or UNAVAILABLE alike
+func normalizeCode(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// parseCodes returns the gRPC status codes of the names.
+func parseCodes(names []string) (map[codes.Code]struct{}, error) {
+	res := make(map[codes.Code]struct{}, len(names))
+	for _, name := range names {
+		c, ok := codeNames[normalizeCode(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid gRPC status code [%s]", name)
+		}
+		res[c] = struct{}{}
+	}
+	return res, nil
+}
+
+// newRetryPolicy returns the policy of the configuration, nil if retries are disabled.
+func newRetryPolicy(config RetryConfig) *retryPolicy {
+	if !config.Enabled || config.MaxAttempts <= 1 {
+		return nil
+	}
+	retryable, err := parseCodes(config.Codes)
+	if err != nil {
+		// NewConfig rejects the invalid codes
+		logger.Warnf("ignoring retry codes: %v", err)
+	}
+	return &retryPolicy{
+		maxAttempts:    config.MaxAttempts,
+		initialBackoff: config.InitialBackoff,
+		maxBackoff:     config.MaxBackoff,
+		retryable:      retryable,
+	}
+}
+
+// retryPolicy tells which failed calls are tried again, and how long to wait before.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retryable      map[codes.Code]struct{}
+}
+
+// retry calls f until it succeeds, fails with a code that is not retryable, or maxAttempts calls are made.
+// It gives up as soon as the context is done.
+func retry[T any](ctx context.Context, p *retryPolicy, f func() (T, error)) (T, error) {
+	res, err := f()
+	if p == nil {
+		return res, err
+	}
+	for attempt := 1; attempt < p.maxAttempts && err != nil && p.retries(err); attempt++ {
+		backoff := p.backoff(attempt)
+		logger.Warnf("query service call failed, retry [%d/%d] in %v: %v", attempt, p.maxAttempts-1, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		case <-timer.C:
+		}
+		res, err = f()
+	}
+	return res, err
+}
+
+func (p *retryPolicy) retries(err error) bool {
+	_, ok := p.retryable[status.Code(err)]
+	return ok
+}
+
+// backoff returns the wait before the retry, doubling with each attempt up to maxBackoff.
+// It is jittered between half and all of it, so that the clients failing together do not retry together.
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.maxBackoff)
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2+1) //nolint:gosec
+}
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setupRetryTest(backoff time.Duration) (*queryservice.RemoteQueryService, *protoqueryservicefakes.FakeQueryServiceClient) {
+	client := &protoqueryservicefakes.FakeQueryServiceClient{}
+	client.GetRowsStub = echoRows
+	config := &queryservice.Config{
+		QueryTimeout: 5 * time.Second,
+		Retry: queryservice.RetryConfig{
+			Enabled:        true,
+			MaxAttempts:    3,
+			InitialBackoff: backoff,
+			MaxBackoff:     2 * backoff,
+			Codes:          []string{"UNAVAILABLE", "resourceExhausted"},
+		},
+	}
+	return queryservice.NewRemoteQueryService(config, protoqueryservice.NewServiceAdapter(client)), client
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	qs, client := setupRetryTest(time.Millisecond)
+	errs := []error{errUnavailable, status.Error(codes.ResourceExhausted, "too many requests")}
+	client.GetRowsStub = func(ctx context.Context, q *protoqueryservice.Query, opts ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		if n := client.GetRowsCallCount(); n <= len(errs) {
+			return nil, errs[n-1]
+		}
+		return echoRows(ctx, q, opts...)
+	}
+
+	v, err := qs.GetState("ns1", "key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value of key1"), v.Raw)
+	require.Equal(t, 3, client.GetRowsCallCount())
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	qs, client := setupRetryTest(time.Millisecond)
+	client.GetRowsStub = nil
+	client.GetRowsReturns(nil, errUnavailable)
+
+	_, err := qs.GetState("ns1", "key1")
+	require.ErrorIs(t, err, errUnavailable)
+	require.Equal(t, 3, client.GetRowsCallCount())
+}
+
+func TestRetryOnlyRetryableCodes(t *testing.T) {
+	qs, client := setupRetryTest(time.Millisecond)
+	client.GetRowsStub = nil
+	client.GetRowsReturns(nil, status.Error(codes.InvalidArgument, "invalid key"))
+
+	_, err := qs.GetState("ns1", "key1")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, 1, client.GetRowsCallCount())
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	qs, client := setupRetryTest(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	client.GetRowsStub = func(context.Context, *protoqueryservice.Query, ...grpc.CallOption) (*protoqueryservice.Rows, error) {
+		cancel()
+		return nil, errUnavailable
+	}
+
+	start := time.Now()
+	_, err := qs.GetStateContext(ctx, "ns1", "key1")
+	require.ErrorIs(t, err, errUnavailable)
+	require.Equal(t, 1, client.GetRowsCallCount())
+	require.Less(t, time.Since(start), time.Minute)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/api/protoqueryservice"
+)
+
+var ErrSessionClosed = fmt.Errorf("query session closed")
+
+// Session is a QueryService whose queries all read the same snapshot of the committer state.
+// Close must be called when the session is no longer needed.
+type Session interface {
+	QueryService
+	Close() error
+}
+
+// SessionProvider opens sessions at the requested isolation level.
+type SessionProvider interface {
+	NewSession(isolationLevel driver.IsolationLevel) Session
+}
+
+// NewSession returns a session reading the state of the committer at the passed isolation level.
+// LevelDefault selects the level of the configuration.
+// When views are disabled, the queries of the session are independent, as the ones of the query service.
+// The view is begun with the first query, so that sessions answered by the local vault cost nothing.
+func (s *RemoteQueryService) NewSession(isolationLevel driver.IsolationLevel) Session {
+	if !s.config.View.Enabled {
+		return &session{service: s}
+	}
+	if isolationLevel == driver.LevelDefault {
+		isolationLevel = isolationLevels[s.config.View.IsolationLevel]
+	}
+	// a view exists on a single replica
+	service := s
+	if b, ok := s.client.(*Balancer); ok {
+		service = &RemoteQueryService{client: b.Pin(), config: s.config, retry: s.retry}
+	}
+	return &session{
+		service: service,
+		params:  protoqueryservice.NewViewParameters(isoLevel(isolationLevel), s.config.View.NonDeferrable, s.config.View.Timeout),
+	}
+}
+
+type session struct {
+	service *RemoteQueryService
+	// params is nil when views are disabled
+	params protoqueryservice.ViewParameters
+
+	mu     sync.Mutex
+	view   protoqueryservice.View
+	closed bool
+}
+
+func (s *session) GetState(ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	return s.GetStateContext(context.Background(), ns, key)
+}
+
+func (s *session) GetStateContext(ctx context.Context, ns driver.Namespace, key driver.PKey) (*driver.VaultValue, error) {
+	if len(ns) == 0 || len(key) == 0 {
+		return nil, ErrInvalidQueryInput
+	}
+
+	view, err := s.beginView(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil {
+		// the lookups outside of views can be batched with the ones of other sessions
+		return s.service.GetStateContext(ctx, ns, key)
+	}
+	res, err := s.service.query(ctx, view, map[driver.Namespace][]driver.PKey{ns: {key}})
+	if err != nil {
+		return nil, err
+	}
+	return lookup(res, ns, key), nil
+}
+
+func (s *session) GetStates(m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+	return s.GetStatesContext(context.Background(), m)
+}
+
+func (s *session) GetStatesContext(ctx context.Context, m map[driver.Namespace][]driver.PKey) (map[driver.Namespace]map[driver.PKey]driver.VaultValue, error) {
+	view, err := s.beginView(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil {
+		return s.service.GetStatesContext(ctx, m)
+	}
+	return s.service.query(ctx, view, m)
+}
+
+// beginView begins the view of the session with its first query, under the context of that query.
+func (s *session) beginView(ctx context.Context) (protoqueryservice.View, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrSessionClosed
+	}
+	if s.params == nil || s.view != nil {
+		return s.view, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.service.config.QueryTimeout)
+	defer cancel()
+	view, err := s.service.client.BeginView(ctx, s.params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot begin view: %w", err)
+	}
+	logger.Debugf("QS began view [%s] at isolation level [%d]", view.GetId(), s.params.GetIsoLevel())
+	s.view = view
+	return view, nil
+}
+
+// Close ends the view of the session, if one was begun.
+func (s *session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.view == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.service.config.QueryTimeout)
+	defer cancel()
+	if _, err := s.service.client.EndView(ctx, s.view); err != nil {
+		return fmt.Errorf("cannot end view [%s]: %w", s.view.GetId(), err)
+	}
+	logger.Debugf("QS ended view [%s]", s.view.GetId())
+	return nil
+}
+
+// isolationLevels maps the isolation levels of the configuration to the ones of the vault
+var isolationLevels = map[string]driver.IsolationLevel{
+	"":                driver.LevelSerializable,
+	"serializable":    driver.LevelSerializable,
+	"repeatableRead":  driver.LevelRepeatableRead,
+	"readCommitted":   driver.LevelReadCommitted,
+	"readUncommitted": driver.LevelReadUncommitted,
+}
+
+// isoLevel maps the isolation levels of the vault to the ones the committer supports,
+// picking the closest stronger level.
+func isoLevel(level driver.IsolationLevel) protoqueryservice.IsoLevel {
+	switch level {
+	case driver.LevelReadUncommitted:
+		return protoqueryservice.ReadUncommitted
+	case driver.LevelReadCommitted, driver.LevelWriteCommitted:
+		return protoqueryservice.ReadCommitted
+	case driver.LevelRepeatableRead, driver.LevelSnapshot:
+		return protoqueryservice.RepeatableRead
+	default:
+		return protoqueryservice.Serializable
+	}
+}
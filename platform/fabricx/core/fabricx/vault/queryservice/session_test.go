@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/committer/v2/protoqueryservice/protoqueryservicefakes"
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionTest(t *testing.T, view queryservice.ViewConfig) (*queryservice.RemoteQueryService, *protoqueryservicefakes.FakeQueryServiceClient) {
+	t.Helper()
+	config := &queryservice.Config{QueryTimeout: 5 * time.Second, View: view}
+
+	client := &protoqueryservicefakes.FakeQueryServiceClient{}
+	client.BeginViewReturns(&protoqueryservice.View{Id: "view1"}, nil)
+	client.EndViewReturns(&protoqueryservice.View{Id: "view1"}, nil)
+	client.GetRowsReturns(&protoqueryservice.Rows{}, nil)
+	return queryservice.NewRemoteQueryService(config, protoqueryservice.NewServiceAdapter(client)), client
+}
+
+func TestSession(t *testing.T) {
+	qs, client := setupSessionTest(t, queryservice.ViewConfig{Enabled: true, IsolationLevel: "repeatableRead", Timeout: time.Second})
+
+	s := qs.NewSession(driver.LevelDefault)
+	require.Equal(t, 0, client.BeginViewCallCount())
+
+	_, err := s.GetState("ns1", "key1")
+	require.NoError(t, err)
+	_, err = s.GetStates(map[driver.Namespace][]driver.PKey{"ns1": {"key2", "key3"}})
+	require.NoError(t, err)
+
+	// a single view is begun and tags every query
+	require.Equal(t, 1, client.BeginViewCallCount())
+	_, params, _ := client.BeginViewArgsForCall(0)
+	require.Equal(t, protoqueryservice.IsoLevel_RepeatableRead, params.GetIsoLevel())
+	require.Equal(t, uint64(1000), params.GetTimeoutMilliseconds())
+	require.Equal(t, 2, client.GetRowsCallCount())
+	for i := 0; i < client.GetRowsCallCount(); i++ {
+		_, q, _ := client.GetRowsArgsForCall(i)
+		require.Equal(t, "view1", q.GetView().GetId())
+	}
+
+	require.NoError(t, s.Close())
+	require.Equal(t, 1, client.EndViewCallCount())
+	_, view, _ := client.EndViewArgsForCall(0)
+	require.Equal(t, "view1", view.GetId())
+
+	// closing twice ends the view once
+	require.NoError(t, s.Close())
+	require.Equal(t, 1, client.EndViewCallCount())
+
+	_, err = s.GetState("ns1", "key1")
+	require.ErrorIs(t, err, queryservice.ErrSessionClosed)
+}
+
+func TestSessionIsolationLevel(t *testing.T) {
+	qs, client := setupSessionTest(t, queryservice.ViewConfig{Enabled: true})
+
+	s := qs.NewSession(driver.LevelReadCommitted)
+	_, err := s.GetState("ns1", "key1")
+	require.NoError(t, err)
+	_, params, _ := client.BeginViewArgsForCall(0)
+	require.Equal(t, protoqueryservice.IsoLevel_ReadCommitted, params.GetIsoLevel())
+
+	s = qs.NewSession(driver.LevelDefault)
+	_, err = s.GetState("ns1", "key1")
+	require.NoError(t, err)
+	_, params, _ = client.BeginViewArgsForCall(1)
+	require.Equal(t, protoqueryservice.IsoLevel_Serializable, params.GetIsoLevel())
+}
+
+func TestSessionWithoutView(t *testing.T) {
+	qs, client := setupSessionTest(t, queryservice.ViewConfig{})
+
+	s := qs.NewSession(driver.LevelSerializable)
+	_, err := s.GetState("ns1", "key1")
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	require.Equal(t, 0, client.BeginViewCallCount())
+	require.Equal(t, 0, client.EndViewCallCount())
+	_, q, _ := client.GetRowsArgsForCall(0)
+	require.Nil(t, q.GetView())
+}
+
+func TestSessionNotClosedWithoutQueries(t *testing.T) {
+	qs, client := setupSessionTest(t, queryservice.ViewConfig{Enabled: true})
+
+	require.NoError(t, qs.NewSession(driver.LevelDefault).Close())
+	require.Equal(t, 0, client.BeginViewCallCount())
+	require.Equal(t, 0, client.EndViewCallCount())
+}
@@ -0,0 +1,194 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+var errNoPeerCertificate = errors.New("no certificate presented by the query service")
+
+// TLSConfig returns the TLS configuration to connect to the endpoint.
+// The server certificate is verified against the root certificates of the endpoint, or the system ones if none is set.
+// The client certificate is presented when the server asks for it.
+// The certificate files are read again when they change on disk, so that they can be rotated without restart:
+// the new certificates apply to the connections established afterward.
+func TLSConfig(endpoint Endpoint) (*tls.Config, error) {
+	if (len(endpoint.TLSClientCertFile) == 0) != (len(endpoint.TLSClientKeyFile) == 0) {
+		return nil, fmt.Errorf("client certificate and key must be set together for query service [%s]", endpoint.Address)
+	}
+
+	r := &certReloader{
+		rootCertFiles:  endpoint.rootCertFiles(),
+		clientCertFile: endpoint.TLSClientCertFile,
+		clientKeyFile:  endpoint.TLSClientKeyFile,
+		serverName:     endpoint.TLSServerNameOverride,
+	}
+	if len(r.serverName) == 0 {
+		r.serverName = hostname(endpoint.Address)
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: endpoint.TLSServerNameOverride,
+		// the server certificate is verified by VerifyConnection, against the current root certificates
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection:   r.verifyConnection,
+	}
+	if len(r.clientCertFile) > 0 {
+		config.GetClientCertificate = r.clientCertificate
+	}
+	return config, nil
+}
+
+func (e Endpoint) rootCertFiles() []string {
+	var files []string
+	if len(e.TLSRootCertFile) > 0 {
+		files = append(files, e.TLSRootCertFile)
+	}
+	return append(files, e.TLSRootCertFiles...)
+}
+
+func hostname(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// certReloader holds the certificates of an endpoint, and reloads them when their files change.
+type certReloader struct {
+	rootCertFiles  []string
+	clientCertFile string
+	clientKeyFile  string
+	serverName     string
+
+	mu sync.Mutex
+	// stamps identify the version of the files the certificates were loaded from
+	stamps     map[string]fileStamp
+	roots      *x509.CertPool
+	clientCert *tls.Certificate
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (r *certReloader) files() []string {
+	if len(r.clientCertFile) == 0 {
+		return r.rootCertFiles
+	}
+	return append([]string{r.clientCertFile, r.clientKeyFile}, r.rootCertFiles...)
+}
+
+// current returns the certificates, reloading them first if a file changed.
+// If the new files cannot be loaded, for instance because they are being rewritten, the previous certificates are kept.
+func (r *certReloader) current() (*x509.CertPool, *tls.Certificate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.changed() {
+		if err := r.reloadLocked(); err != nil {
+			logger.Warnf("cannot reload query service certificates, keep the previous ones: %v", err)
+		}
+	}
+	return r.roots, r.clientCert
+}
+
+func (r *certReloader) changed() bool {
+	for _, file := range r.files() {
+		stamp, err := stat(file)
+		if err != nil || stamp != r.stamps[file] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reloadLocked()
+}
+
+func (r *certReloader) reloadLocked() error {
+	stamps := make(map[string]fileStamp, len(r.files()))
+	for _, file := range r.files() {
+		stamp, err := stat(file)
+		if err != nil {
+			return fmt.Errorf("cannot read certificate file: %w", err)
+		}
+		stamps[file] = stamp
+	}
+
+	var roots *x509.CertPool
+	if len(r.rootCertFiles) > 0 {
+		roots = x509.NewCertPool()
+		for _, file := range r.rootCertFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("cannot read root certificate file: %w", err)
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return fmt.Errorf("no certificate found in root certificate file [%s]", file)
+			}
+		}
+	}
+
+	var clientCert *tls.Certificate
+	if len(r.clientCertFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(r.clientCertFile, r.clientKeyFile)
+		if err != nil {
+			return fmt.Errorf("cannot load client certificate [%s]: %w", r.clientCertFile, err)
+		}
+		clientCert = &cert
+	}
+
+	r.stamps, r.roots, r.clientCert = stamps, roots, clientCert
+	return nil
+}
+
+func stat(file string) (fileStamp, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func (r *certReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	_, cert := r.current()
+	return cert, nil
+}
+
+func (r *certReloader) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errNoPeerCertificate
+	}
+	roots, _ := r.current()
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       r.serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
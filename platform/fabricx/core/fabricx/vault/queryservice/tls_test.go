@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package queryservice_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-x-endorser/platform/fabricx/core/fabricx/vault/queryservice"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(0, 0, 1),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a certificate for localhost, and its key, in PEM
+func (ca *testCA) issue(t *testing.T, name string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(0, 0, 1),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) issueFiles(t *testing.T, name string) (string, string) {
+	t.Helper()
+	cert, key := ca.issue(t, name)
+	dir := t.TempDir()
+	certPath, keyPath := path.Join(dir, name+".crt"), path.Join(dir, name+".key")
+	writeFile(t, certPath, cert)
+	writeFile(t, keyPath, key)
+	return certPath, keyPath
+}
+
+// writeFile writes the file, and moves its modification time forward,
+// so that the rewrite is noticed even where the file times are coarse
+func writeFile(t *testing.T, file string, content []byte) {
+	t.Helper()
+	stamp := time.Now()
+	if info, err := os.Stat(file); err == nil {
+		stamp = info.ModTime().Add(time.Second)
+	}
+	require.NoError(t, os.WriteFile(file, content, 0o600))
+	require.NoError(t, os.Chtimes(file, stamp, stamp))
+}
+
+// startTLSServer accepts connections requiring a client certificate issued by clientCA,
+// and replies with the common name of the client.
+func startTLSServer(t *testing.T, ca, clientCA *testCA) string {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "server")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if err := tlsConn.Handshake(); err == nil {
+				_, _ = tlsConn.Write([]byte(tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName + "\n"))
+			}
+			_ = conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// dial returns the common name of the client certificate the server received
+func dial(t *testing.T, endpoint queryservice.Endpoint) (string, error) {
+	t.Helper()
+	config, err := queryservice.TLSConfig(endpoint)
+	require.NoError(t, err)
+	conn, err := tls.Dial("tcp", endpoint.Address, config)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	return bufio.NewReader(conn).ReadString('\n')
+}
+
+func TestTLSConfigMultipleRoots(t *testing.T) {
+	ca1, ca2, clientCA := newTestCA(t, "ca1"), newTestCA(t, "ca2"), newTestCA(t, "clientCA")
+	dir := t.TempDir()
+	root1, root2 := path.Join(dir, "ca1.crt"), path.Join(dir, "ca2.crt")
+	writeFile(t, root1, ca1.pem)
+	writeFile(t, root2, ca2.pem)
+	certPath, keyPath := clientCA.issueFiles(t, "client")
+
+	for _, ca := range []*testCA{ca1, ca2} {
+		cn, err := dial(t, queryservice.Endpoint{
+			Address:           startTLSServer(t, ca, clientCA),
+			TLSRootCertFile:   root1,
+			TLSRootCertFiles:  []string{root2},
+			TLSClientCertFile: certPath,
+			TLSClientKeyFile:  keyPath,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "client\n", cn)
+	}
+
+	// servers are verified against their name
+	addr := startTLSServer(t, ca1, clientCA)
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	_, err = dial(t, queryservice.Endpoint{
+		Address:               "127.0.0.1:" + port,
+		TLSRootCertFile:       root1,
+		TLSServerNameOverride: "committer.example.com",
+		TLSClientCertFile:     certPath,
+		TLSClientKeyFile:      keyPath,
+	})
+	require.ErrorContains(t, err, "committer.example.com")
+}
+
+func TestTLSConfigReload(t *testing.T) {
+	ca1, ca2, clientCA := newTestCA(t, "ca1"), newTestCA(t, "ca2"), newTestCA(t, "clientCA")
+	dir := t.TempDir()
+	root := path.Join(dir, "ca.crt")
+	writeFile(t, root, ca1.pem)
+	certPath, keyPath := clientCA.issueFiles(t, "client1")
+
+	server1, server2 := startTLSServer(t, ca1, clientCA), startTLSServer(t, ca2, clientCA)
+	endpoint := queryservice.Endpoint{
+		TLSRootCertFile:   root,
+		TLSClientCertFile: certPath,
+		TLSClientKeyFile:  keyPath,
+	}
+	config, err := queryservice.TLSConfig(endpoint)
+	require.NoError(t, err)
+	handshake := func(addr string) (string, error) {
+		conn, err := tls.Dial("tcp", addr, config.Clone())
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = conn.Close() }()
+		return bufio.NewReader(conn).ReadString('\n')
+	}
+
+	cn, err := handshake(server1)
+	require.NoError(t, err)
+	require.Equal(t, "client1\n", cn)
+	_, err = handshake(server2)
+	require.Error(t, err)
+
+	// the rotated certificates apply to the next connections
+	writeFile(t, root, ca2.pem)
+	cert, key := clientCA.issue(t, "client2")
+	writeFile(t, certPath, cert)
+	writeFile(t, keyPath, key)
+
+	cn, err = handshake(server2)
+	require.NoError(t, err)
+	require.Equal(t, "client2\n", cn)
+	_, err = handshake(server1)
+	require.Error(t, err)
+
+	// a half-written rotation keeps the previous certificates
+	writeFile(t, keyPath, []byte("not a key"))
+	cn, err = handshake(server2)
+	require.NoError(t, err)
+	require.Equal(t, "client2\n", cn)
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package vault
 
 import (
+	"github.com/hyperledger-labs/fabric-smart-client/pkg/utils/errors"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/driver"
 	"github.com/hyperledger-labs/fabric-smart-client/platform/common/services/logging"
 	fdriver "github.com/hyperledger-labs/fabric-smart-client/platform/fabric/driver"
@@ -24,7 +25,7 @@ func New(
 	vaultStore driver.VaultStore,
 	channel string,
 	adapterProvider protoblocktx.Provider,
-	_ queryservice.Provider,
+	queryServiceProvider queryservice.Provider,
 	metricsProvider metrics.Provider,
 	tracerProvider trace.TracerProvider,
 ) (*Vault, error) {
@@ -32,15 +33,49 @@ func New(
 	if err != nil {
 		return nil, err
 	}
-	// TODO: this is an example how to integrate the query service into the vault and let all getters communicate with the committer directly
-	// queryService, err := queryServiceProvider.Get(configService.NetworkName(), channel)
-	// if err != nil {
-	//	 return nil, nil, fmt.Errorf("could not get mapping provider for %s: %v", channel, err)
-	// }
-	//
-	// wrapp our store with our remote proxy
-	// s := queryservice.NewProxyStore(persistence, queryService)
-
 	cachedVault := vault.NewCachedVault(vaultStore, configService.VaultTXStoreCacheSize())
-	return NewVault(cachedVault, adapter, metricsProvider, tracerProvider), nil
+
+	// with the proxy enabled, states missing locally are read from the committer
+	proxy, err := proxyEnabled(configService)
+	if err != nil {
+		return nil, err
+	}
+	if proxy {
+		queryService, err := queryServiceProvider.Get(configService.NetworkName(), channel)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get query service for [%s]", channel)
+		}
+		logger.Debugf("read states missing in the vault of [%s] from the query service", channel)
+		cachedVault = queryservice.NewProxyStore(cachedVault, queryService)
+
+		// namespace versions are taken from the policies of the committer,
+		// and refreshed when a committed transaction updates the namespaces
+		if source, ok := queryService.(NamespaceVersionSource); ok {
+			cache := NewNamespaceVersionCache(source)
+			cachedVault = NewNamespaceVersionInvalidator(cachedVault, cache)
+			return NewVault(cachedVault, adapter, cache, metricsProvider, tracerProvider), nil
+		}
+	}
+	return NewVault(cachedVault, adapter, nil, metricsProvider, tracerProvider), nil
+}
+
+type proxyConfigService interface {
+	queryservice.ConfigService
+	GetBool(key string) bool
+}
+
+// proxyEnabled tells whether the vault reads the states missing locally from the query service,
+// as set by the `vault.queryService.proxy` key. The proxy needs query service endpoints.
+func proxyEnabled(configService proxyConfigService) (bool, error) {
+	if !configService.GetBool("vault.queryService.proxy") {
+		return false, nil
+	}
+	config, err := queryservice.NewConfig(configService)
+	if err != nil {
+		return false, err
+	}
+	if len(config.Endpoints) == 0 {
+		return false, errors.New("vault.queryService.proxy is enabled, but no query service endpoints are configured")
+	}
+	return true, nil
 }
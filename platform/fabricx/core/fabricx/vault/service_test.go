@@ -0,0 +1,44 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vault
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+type viperConfigService struct{ *viper.Viper }
+
+func newViperConfigService(c map[string]any) viperConfigService {
+	v := viper.New()
+	for k, val := range c {
+		v.Set(k, val)
+	}
+	return viperConfigService{Viper: v}
+}
+
+func (c viperConfigService) UnmarshalKey(key string, rawVal interface{}) error {
+	return c.Viper.UnmarshalKey(key, rawVal)
+}
+
+func TestProxyEnabled(t *testing.T) {
+	endpoints := []map[string]any{{"address": "localhost:7001"}}
+
+	// the query service endpoints alone do not enable the proxy
+	proxy, err := proxyEnabled(newViperConfigService(map[string]any{"queryService.endpoints": endpoints}))
+	require.NoError(t, err)
+	require.False(t, proxy)
+
+	proxy, err = proxyEnabled(newViperConfigService(map[string]any{"vault.queryService.proxy": true, "queryService.endpoints": endpoints}))
+	require.NoError(t, err)
+	require.True(t, proxy)
+
+	_, err = proxyEnabled(newViperConfigService(map[string]any{"vault.queryService.proxy": true}))
+	require.EqualError(t, err, "vault.queryService.proxy is enabled, but no query service endpoints are configured")
+}
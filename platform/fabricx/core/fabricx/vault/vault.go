@@ -24,13 +24,17 @@ type (
 )
 
 // NewVault returns a new instance of Vault.
-func NewVault(vaultStore vault2.CachedVaultStore, adapter protoblocktx.Marshaller, metricsProvider metrics.Provider, tracerProvider trace.TracerProvider) *Vault {
+// The namespace versions of the transactions are read from the local vault, when versions is nil.
+func NewVault(vaultStore vault2.CachedVaultStore, adapter protoblocktx.Marshaller, versions NamespaceVersionResolver, metricsProvider metrics.Provider, tracerProvider trace.TracerProvider) *Vault {
 	m := NewMarshaller(adapter)
+	if versions == nil {
+		versions = localNamespaceVersions{}
+	}
 
 	interceptor := func(logger vault.Logger, ctx context.Context, rwSet vault.ReadWriteSet, qe vault.VersionedQueryExecutor, vaultStore vault.TxStatusStore, txID driver.TxID) vault.TxInterceptor {
 		logger.Debugf("create new interceptor for [txID=%v]", txID)
 		ci := vault.NewInterceptor(logger, ctx, rwSet, qe, vaultStore, txID, fdriver.ValidationCodeProvider, nil, &CounterBasedVersionComparator{})
-		return newInterceptor(ci, txID, qe, m)
+		return newInterceptor(ctx, ci, txID, qe, m, versions)
 	}
 
 	return vault.New(